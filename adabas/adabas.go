@@ -17,6 +17,7 @@ package adabas
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 
@@ -85,6 +86,11 @@ func (ada *Adabas) URL() string {
 	return ada.dbURL
 }
 
+// DriverType database driver type used for dialect specific SQL
+func (ada *Adabas) DriverType() common.ReferenceType {
+	return common.AdabasType
+}
+
 // Maps database maps, tables or views
 func (ada *Adabas) Maps() ([]string, error) {
 	if ada.dbTableNames == nil {
@@ -305,6 +311,80 @@ func (ada *Adabas) GetTableColumn(tableName string) ([]string, error) {
 	return conn.GetMaps()
 }
 
+// TableColumns get map field definitions of the Adabas map
+func (ada *Adabas) TableColumns(tableName string) ([]*common.Column, error) {
+	con, err := ada.Open()
+	if err != nil {
+		return nil, err
+	}
+	conn := con.(*adabas.Connection)
+	defer ada.Close()
+	adabasMap, _, err := adabas.SearchMapRepository(conn.ID, tableName)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]*common.Column, 0, len(adabasMap.Fields))
+	for _, f := range adabasMap.Fields {
+		columns = append(columns, mapFieldColumn(f))
+	}
+	return columns, nil
+}
+
+// maxDecimalPrecision precision of fractional fields without length, the
+// digits of the largest packed field
+const maxDecimalPrecision = 29
+
+// mapFieldColumn convert Adabas map field into column definition
+func mapFieldColumn(f *adabas.MapField) *common.Column {
+	column := &common.Column{Name: f.LongName, Length: uint16(f.Length), Nullable: true}
+	switch strings.TrimSpace(f.FormatType) {
+	case "B":
+		column.DataType = common.Bytes
+	case "I":
+		column.DataType = common.Integer
+	case "F":
+		column.DataType = common.Float
+		column.Length = 0
+	case "N", "U", "P":
+		column.DataType = common.Integer
+		column.Length = 8
+		if fractional := fractionalShift(f.ContentType); fractional > 0 {
+			// unpacked fields have one digit per byte, packed two minus sign
+			precision := int(f.Length)
+			if strings.TrimSpace(f.FormatType) == "P" {
+				precision = 2*precision - 1
+			}
+			if precision <= fractional {
+				precision = maxDecimalPrecision
+			}
+			column.DataType = common.Decimal
+			column.Length = uint16(precision)
+			column.Digits = uint8(fractional)
+		}
+	default:
+		column.DataType = common.Alpha
+		if f.Length == 0 {
+			column.DataType = common.Text
+		}
+	}
+	return column
+}
+
+// fractionalShift number of decimal places of the map field content type
+// like 'fractionalshift=2'
+func fractionalShift(contentType string) int {
+	for _, c := range strings.Split(contentType, ",") {
+		name, value, found := strings.Cut(c, "=")
+		if found && strings.EqualFold(strings.TrimSpace(name), "fractionalshift") {
+			fractional, err := strconv.Atoi(strings.TrimSpace(value))
+			if err == nil && fractional > 0 {
+				return fractional
+			}
+		}
+	}
+	return 0
+}
+
 // Query query database records with search or SELECT
 func (ada *Adabas) Query(search *common.Query, f common.ResultFunction) (*common.Result, error) {
 	search.Driver = common.AdabasType
@@ -340,7 +420,18 @@ func (ada *Adabas) Query(search *common.Query, f common.ResultFunction) (*common
 		return nil, err
 	}
 
-	cursor, err := request.ReadPhysicalWithCursoring()
+	var cursor *adabas.Cursoring
+	descriptors := orderDescriptors(search.Order)
+	switch {
+	case search.Search != "" && descriptors != "":
+		cursor, err = request.SearchAndOrderWithCursoring(search.Search, descriptors)
+	case search.Search != "":
+		cursor, err = request.ReadLogicalWithCursoring(search.Search)
+	case descriptors != "":
+		cursor, err = request.ReadLogicalByCursoring(descriptors)
+	default:
+		cursor, err = request.ReadPhysicalWithCursoring()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -384,6 +475,19 @@ func (ada *Adabas) Query(search *common.Query, f common.ResultFunction) (*common
 	return result, nil
 }
 
+// orderDescriptors comma-separated descriptors of the query order like
+// 'name:ASC', Adabas reads logically in ascending order only
+func orderDescriptors(order []string) string {
+	descriptors := make([]string, 0, len(order))
+	for _, o := range order {
+		name, _, _ := strings.Cut(o, ":")
+		if name = strings.TrimSpace(name); name != "" {
+			descriptors = append(descriptors, name)
+		}
+	}
+	return strings.Join(descriptors, ",")
+}
+
 // CreateTable create a new table
 func (ada *Adabas) CreateTable(string, any) error {
 	return errorrepo.NewError("DB065535")
@@ -394,6 +498,16 @@ func (ada *Adabas) AdaptTable(string, any) error {
 	return errorrepo.NewError("DB065535")
 }
 
+// PlanAdaptTable generate plan to adapt table to new structure
+func (ada *Adabas) PlanAdaptTable(string, any) (*common.AdaptPlan, error) {
+	return nil, errorrepo.NewError("DB065535")
+}
+
+// ApplyAdaptPlan apply adapt plan to table
+func (ada *Adabas) ApplyAdaptPlan(*common.AdaptPlan, bool) error {
+	return errorrepo.NewError("DB065535")
+}
+
 // DeleteTable delete a table
 func (ada *Adabas) DeleteTable(string) error {
 	return errorrepo.NewError("DB065535")
@@ -404,6 +518,11 @@ func (ada *Adabas) Batch(batch string) error {
 	return errorrepo.NewError("DB065535")
 }
 
+// Execute execute SQL statement with parameters
+func (ada *Adabas) Execute(statement string, args ...any) (int64, error) {
+	return -1, errorrepo.NewError("DB065535")
+}
+
 // BatchSelect batch SQL query in table with values returned
 func (ada *Adabas) BatchSelect(batch string) ([][]interface{}, error) {
 	return nil, errorrepo.NewError("DB065535")
@@ -468,3 +587,174 @@ func (ada *Adabas) Stream(search *common.Query, sf common.StreamFunction) error
 	}
 	return nil
 }
+
+// StreamWrite write the reader data in LOB segments into the field of all
+// records matching the search. The old content of the LOB is replaced,
+// on errors the transaction is backed out.
+func (ada *Adabas) StreamWrite(search *common.Query, reader io.Reader) (int64, error) {
+	con, err := ada.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer ada.Close()
+	conn := con.(*adabas.Connection)
+	sread, err := conn.CreateMapReadRequest(search.TableName)
+	if err != nil {
+		return 0, err
+	}
+	err = sread.QueryFields("")
+	if err != nil {
+		return 0, err
+	}
+	result, err := sread.ReadLogicalWith(search.Search)
+	if err != nil {
+		return 0, err
+	}
+	if result.NrRecords() == 0 {
+		return 0, errorrepo.NewError("DB000015")
+	}
+	isns := make([]adatypes.Isn, 0, len(result.Values))
+	for _, v := range result.Values {
+		isns = append(isns, v.Isn)
+	}
+	field := search.Fields[0]
+	store, err := conn.CreateMapStoreRequest(search.TableName)
+	if err != nil {
+		return 0, err
+	}
+	err = store.StoreFields(field)
+	if err != nil {
+		return 0, err
+	}
+	lob, err := conn.CreateMapStoreRequest(search.TableName)
+	if err != nil {
+		return 0, err
+	}
+	n, err := common.StreamBlocks(reader, search.Blocksize, func(offset int64, block []byte) error {
+		log.Log.Debugf("Write LOB segment of %d bytes at %d", len(block), offset)
+		for _, isn := range isns {
+			var err error
+			if offset == 0 {
+				// first segment replaces the old content, it is empty
+				// if the reader contains no data
+				err = replaceLOB(store, isn, field, block)
+			} else {
+				err = lob.UpdateLOBRecord(isn, field, uint64(offset), block)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if berr := conn.BackoutTransaction(); berr != nil {
+			log.Log.Debugf("Backout LOB write error: %v", berr)
+		}
+		return n, err
+	}
+	return n, store.EndTransaction()
+}
+
+// replaceLOB replace the complete LOB field content of the record
+func replaceLOB(store *adabas.StoreRequest, isn adatypes.Isn, field string, data []byte) error {
+	record, err := store.CreateRecord()
+	if err != nil {
+		return err
+	}
+	record.Isn = isn
+	err = record.SetValue(field, data)
+	if err != nil {
+		return err
+	}
+	return store.Update(record)
+}
+
+// OpenLOB open reader of the LOB field of the first record found by the
+// search. LOB segments are read sequentially, so the size is evaluated
+// reading all segments once and reading backwards restarts at the begin.
+func (ada *Adabas) OpenLOB(search *common.Query) (common.LOBReader, error) {
+	con, err := ada.Open()
+	if err != nil {
+		return nil, err
+	}
+	conn := con.(*adabas.Connection)
+	sread, err := conn.CreateMapReadRequest(search.TableName)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	err = sread.QueryFields("")
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	result, err := sread.ReadLogicalWith(search.Search)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if result.NrRecords() == 0 {
+		conn.Close()
+		return nil, errorrepo.NewError("DB000015")
+	}
+	blocksize := search.Blocksize
+	if blocksize <= 0 {
+		blocksize = common.DefaultReadBlocksize
+	}
+	segments := &lobSegments{conn: conn, table: search.TableName, field: search.Fields[0],
+		isn: result.Values[0].Isn, blocksize: blocksize}
+	size := int64(0)
+	for {
+		segment, err := segments.read(size)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		size += int64(len(segment))
+		if len(segment) < int(blocksize) {
+			break
+		}
+	}
+	return common.NewLOBReader(size, blocksize, func(offset int64, length int32) ([]byte, error) {
+		return segments.read(offset)
+	}, func() error {
+		conn.Close()
+		return nil
+	}), nil
+}
+
+// lobSegments sequential LOB segment reader of a record
+type lobSegments struct {
+	conn      *adabas.Connection
+	table     string
+	field     string
+	isn       adatypes.Isn
+	blocksize int32
+	request   *adabas.ReadRequest
+	next      int64
+}
+
+// read read segment at the block aligned offset, the read request is
+// restarted if the offset is before the next segment
+func (ls *lobSegments) read(offset int64) ([]byte, error) {
+	if ls.request == nil || offset < ls.next {
+		request, err := ls.conn.CreateMapReadRequest(ls.table)
+		if err != nil {
+			return nil, err
+		}
+		ls.request = request
+		ls.next = 0
+	}
+	for {
+		segment, err := ls.request.ReadLOBSegment(ls.isn, ls.field, uint64(ls.blocksize))
+		if err != nil {
+			ls.request = nil
+			return nil, err
+		}
+		ls.next += int64(len(segment))
+		if ls.next > offset || len(segment) < int(ls.blocksize) {
+			return segment, nil
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/tknie/adabas-go-api/adabas"
 	"github.com/tknie/flynn/common"
 )
 
@@ -59,3 +60,27 @@ func TestAdaSearch(t *testing.T) {
 	assert.Equal(t, "aaa=['XXX'0x00:'XXX'0xff]", search)
 
 }
+
+func TestAdaOrderDescriptors(t *testing.T) {
+	assert.Equal(t, "", orderDescriptors(nil))
+	assert.Equal(t, "AE", orderDescriptors([]string{"AE:ASC"}))
+	assert.Equal(t, "AE,AA", orderDescriptors([]string{"AE:DESC", " AA", ""}))
+}
+
+func TestAdaMapFieldColumn(t *testing.T) {
+	column := mapFieldColumn(&adabas.MapField{LongName: "COUNT", FormatType: "P", Length: 4})
+	assert.Equal(t, common.Integer, column.DataType)
+	assert.Equal(t, uint16(8), column.Length)
+	column = mapFieldColumn(&adabas.MapField{LongName: "SALARY", FormatType: "P", Length: 5,
+		ContentType: "charset=,fractionalshift=2"})
+	assert.Equal(t, common.Decimal, column.DataType)
+	assert.Equal(t, uint16(9), column.Length)
+	assert.Equal(t, uint8(2), column.Digits)
+	column = mapFieldColumn(&adabas.MapField{LongName: "RATE", FormatType: "N", Length: 6,
+		ContentType: "FractionalShift=3"})
+	assert.Equal(t, common.Decimal, column.DataType)
+	assert.Equal(t, uint16(6), column.Length)
+	assert.Equal(t, uint8(3), column.Digits)
+	column = mapFieldColumn(&adabas.MapField{LongName: "AMOUNT", FormatType: "U", ContentType: "fractionalshift=2"})
+	assert.Equal(t, uint16(maxDecimalPrecision), column.Length)
+}
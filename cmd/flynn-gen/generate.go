@@ -0,0 +1,182 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/tknie/flynn/common"
+)
+
+// generatedHeader marks generated files, no date is added to keep
+// regeneration idempotent
+const generatedHeader = "// Code generated by flynn-gen. DO NOT EDIT.\n"
+
+// NullMode Go representation of nullable columns
+type NullMode string
+
+const (
+	NullPointer NullMode = "pointer"
+	NullSQL     NullMode = "sql"
+)
+
+// tableDefinition table or Adabas map read out of the database
+type tableDefinition struct {
+	name    string
+	isn     bool
+	columns []*common.Column
+}
+
+// goType Go base type and sql.Null type of the column
+func goType(column *common.Column) (string, string) {
+	switch column.DataType {
+	case common.Integer:
+		switch {
+		case column.Length == 1 || column.Length == 2:
+			return "int16", "sql.NullInt16"
+		case column.Length > 0 && column.Length <= 4:
+			return "int32", "sql.NullInt32"
+		}
+		return "int64", "sql.NullInt64"
+	case common.Number:
+		return "int64", "sql.NullInt64"
+	case common.Decimal:
+		if column.Digits == 0 {
+			return "int64", "sql.NullInt64"
+		}
+		return "float64", "sql.NullFloat64"
+	case common.Float:
+		return "float64", "sql.NullFloat64"
+	case common.Boolean:
+		return "bool", "sql.NullBool"
+	case common.CurrentTimestamp, common.Date:
+		return "time.Time", "sql.NullTime"
+	case common.Bytes, common.BLOB, common.Bit:
+		return "[]byte", "[]byte"
+	default:
+		return "string", "sql.NullString"
+	}
+}
+
+// fieldType Go field type of the column respecting nullable columns
+func fieldType(column *common.Column, nullMode NullMode) string {
+	base, nullType := goType(column)
+	if !column.Nullable || column.Key || base == "[]byte" {
+		return base
+	}
+	if nullMode == NullSQL {
+		return nullType
+	}
+	return "*" + base
+}
+
+// fieldTag flynn tag of the column
+func fieldTag(column *common.Column) string {
+	tag := column.Name
+	if column.Key {
+		tag += ":key"
+	}
+	switch column.DataType {
+	case common.Alpha, common.Character, common.Unicode:
+		if column.Length > 0 {
+			if !column.Key {
+				tag += ":"
+			}
+			tag += ":" + strconv.Itoa(int(column.Length))
+		}
+	}
+	return tag
+}
+
+// goName exported Go name of the database name
+func goName(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i != -1 {
+		name = name[i+1:]
+	}
+	var buffer bytes.Buffer
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, p := range parts {
+		if strings.ToUpper(p) == p || strings.ToLower(p) == p {
+			p = strings.ToLower(p)
+		}
+		switch p {
+		case "id", "url", "uuid", "isn":
+			buffer.WriteString(strings.ToUpper(p))
+		default:
+			buffer.WriteString(strings.ToUpper(p[:1]) + p[1:])
+		}
+	}
+	if buffer.Len() == 0 || unicode.IsDigit(rune(buffer.String()[0])) {
+		return "X" + buffer.String()
+	}
+	return buffer.String()
+}
+
+// generate generate formatted Go source of all table structures
+func generate(packageName string, nullMode NullMode, tables []*tableDefinition) ([]byte, error) {
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].name < tables[j].name
+	})
+	imports := make(map[string]bool)
+	var body bytes.Buffer
+	for _, table := range tables {
+		structName := goName(table.name)
+		body.WriteString(fmt.Sprintf("\n// %sTable table name of %s\n", structName, structName))
+		body.WriteString(fmt.Sprintf("const %sTable = %q\n", structName, table.name))
+		body.WriteString(fmt.Sprintf("\n// %s structure of table %s\n", structName, table.name))
+		body.WriteString(fmt.Sprintf("type %s struct {\n", structName))
+		used := make(map[string]int)
+		if table.isn {
+			body.WriteString("ISN uint64 `flynn:\":isn\"`\n")
+			used["ISN"] = 1
+		}
+		for _, column := range table.columns {
+			name := goName(column.Name)
+			if c, ok := used[name]; ok {
+				used[name] = c + 1
+				name += strconv.Itoa(c + 1)
+			} else {
+				used[name] = 1
+			}
+			ft := fieldType(column, nullMode)
+			switch {
+			case strings.Contains(ft, "time."):
+				imports["time"] = true
+			case strings.Contains(ft, "sql."):
+				imports["database/sql"] = true
+			}
+			body.WriteString(fmt.Sprintf("%s %s `flynn:%q`\n", name, ft, fieldTag(column)))
+		}
+		body.WriteString("}\n")
+	}
+	var source bytes.Buffer
+	source.WriteString(generatedHeader)
+	source.WriteString("\npackage " + packageName + "\n")
+	if len(imports) > 0 {
+		importList := make([]string, 0, len(imports))
+		for i := range imports {
+			importList = append(importList, strconv.Quote(i))
+		}
+		sort.Strings(importList)
+		source.WriteString("\nimport (\n" + strings.Join(importList, "\n") + "\n)\n")
+	}
+	source.Write(body.Bytes())
+	return format.Source(source.Bytes())
+}
@@ -0,0 +1,99 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tknie/flynn/common"
+)
+
+func testTables() []*tableDefinition {
+	return []*tableDefinition{
+		{name: "pictures", columns: []*common.Column{
+			{Name: "checksumpicture", DataType: common.Alpha, Length: 40, Key: true},
+			{Name: "title", DataType: common.Alpha, Length: 255, Nullable: true},
+			{Name: "height", DataType: common.Integer, Length: 4, Nullable: true},
+			{Name: "created", DataType: common.CurrentTimestamp},
+			{Name: "media", DataType: common.Bytes, Nullable: true},
+		}},
+		{name: "ALBUM_INFO", columns: []*common.Column{
+			{Name: "ALBUM_ID", DataType: common.Integer, Length: 8, Key: true},
+			{Name: "PRICE", DataType: common.Decimal, Length: 10, Digits: 2, Nullable: true},
+			{Name: "PUBLISHED", DataType: common.Boolean},
+		}},
+	}
+}
+
+func TestGenerateStruct(t *testing.T) {
+	source, err := generate("model", NullPointer, testTables())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, `// Code generated by flynn-gen. DO NOT EDIT.
+
+package model
+
+import (
+	"time"
+)
+
+// AlbumInfoTable table name of AlbumInfo
+const AlbumInfoTable = "ALBUM_INFO"
+
+// AlbumInfo structure of table ALBUM_INFO
+type AlbumInfo struct {
+	AlbumID   int64    `+"`"+`flynn:"ALBUM_ID:key"`+"`"+`
+	Price     *float64 `+"`"+`flynn:"PRICE"`+"`"+`
+	Published bool     `+"`"+`flynn:"PUBLISHED"`+"`"+`
+}
+
+// PicturesTable table name of Pictures
+const PicturesTable = "pictures"
+
+// Pictures structure of table pictures
+type Pictures struct {
+	Checksumpicture string    `+"`"+`flynn:"checksumpicture:key:40"`+"`"+`
+	Title           *string   `+"`"+`flynn:"title::255"`+"`"+`
+	Height          *int32    `+"`"+`flynn:"height"`+"`"+`
+	Created         time.Time `+"`"+`flynn:"created"`+"`"+`
+	Media           []byte    `+"`"+`flynn:"media"`+"`"+`
+}
+`, string(source))
+
+	again, err := generate("model", NullPointer, testTables())
+	assert.NoError(t, err)
+	assert.Equal(t, source, again)
+}
+
+func TestGenerateNullTypes(t *testing.T) {
+	tables := testTables()
+	tables[1].isn = true
+	source, err := generate("legacy", NullSQL, tables)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, string(source), "import (\n\t\"database/sql\"\n\t\"time\"\n)")
+	assert.Contains(t, string(source), "Title           sql.NullString")
+	assert.Contains(t, string(source), "Height          sql.NullInt32")
+	assert.Contains(t, string(source), "ISN       uint64          `flynn:\":isn\"`")
+}
+
+func TestGenerateName(t *testing.T) {
+	assert.Equal(t, "AlbumPictures", goName("album_pictures"))
+	assert.Equal(t, "AlbumID", goName("ALBUM_ID"))
+	assert.Equal(t, "Album", goName("ADMIN.ALBUM"))
+	assert.Equal(t, "MarkDown", goName("markDown"))
+	assert.Equal(t, "X1column", goName("1column"))
+	assert.Equal(t, "PersonnelID", goName("PERSONNEL-ID"))
+}
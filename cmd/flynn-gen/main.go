@@ -0,0 +1,131 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+// flynn-gen generates flynn tagged Go structures out of existing database
+// tables or Adabas maps.
+//
+//	flynn-gen -url postgres://admin:<password>@host:5432/db -tables 'album*,pictures' -o model/tables.go
+//
+// Regenerating an unchanged schema produces identical output, the output
+// file is only rewritten if the content changed. Use -check to verify the
+// generated file is up to date.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/tknie/flynn"
+	"github.com/tknie/flynn/common"
+)
+
+func main() {
+	url := flag.String("url", "", "database URL")
+	tables := flag.String("tables", "", "comma-separated table or map names, patterns like 'album*' are possible")
+	packageName := flag.String("package", "model", "package name of the generated source")
+	output := flag.String("o", "", "output file, standard output if not given")
+	nullMode := flag.String("null", string(NullPointer), "nullable columns as 'pointer' or 'sql' (sql.Null types)")
+	check := flag.Bool("check", false, "check output file is up to date without writing it")
+	flag.Parse()
+
+	if *url == "" || *tables == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *nullMode != string(NullPointer) && *nullMode != string(NullSQL) {
+		fmt.Fprintln(os.Stderr, "Invalid null mode:", *nullMode)
+		os.Exit(2)
+	}
+	source, err := generateFromDatabase(*url, strings.Split(*tables, ","), *packageName, NullMode(*nullMode))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error generating structures:", err)
+		os.Exit(1)
+	}
+	if *output == "" {
+		os.Stdout.Write(source)
+		return
+	}
+	current, err := os.ReadFile(*output)
+	if err == nil && bytes.Equal(current, source) {
+		return
+	}
+	if *check {
+		fmt.Fprintln(os.Stderr, "Generated file not up to date:", *output)
+		os.Exit(1)
+	}
+	err = os.WriteFile(*output, source, 0644)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error writing output:", err)
+		os.Exit(1)
+	}
+}
+
+// generateFromDatabase read table definitions and generate the Go source
+func generateFromDatabase(url string, patterns []string, packageName string, nullMode NullMode) ([]byte, error) {
+	id, err := flynn.Handle(url)
+	if err != nil {
+		return nil, err
+	}
+	defer id.FreeHandler()
+
+	names, err := matchTables(id, patterns)
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]*tableDefinition, 0, len(names))
+	for _, name := range names {
+		columns, err := id.TableColumns(name)
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %v", name, err)
+		}
+		tables = append(tables, &tableDefinition{name: name,
+			isn: id.DriverType() == common.AdabasType, columns: columns})
+	}
+	return generate(packageName, nullMode, tables)
+}
+
+// matchTables evaluate table names out of names and patterns
+func matchTables(id common.RegDbID, patterns []string) ([]string, error) {
+	var available []string
+	names := make([]string, 0)
+	added := make(map[string]bool)
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if !strings.ContainsAny(p, "*?[") {
+			if !added[p] {
+				names = append(names, p)
+				added[p] = true
+			}
+			continue
+		}
+		if available == nil {
+			var err error
+			available, err = id.Tables()
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, t := range available {
+			if ok, _ := path.Match(strings.ToLower(p), strings.ToLower(t)); ok && !added[t] {
+				names = append(names, t)
+				added[t] = true
+			}
+		}
+	}
+	return names, nil
+}
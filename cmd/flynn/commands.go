@@ -0,0 +1,287 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tknie/flynn"
+	"github.com/tknie/flynn/common"
+)
+
+// tablesCommand list tables or maps of the database
+func tablesCommand(out io.Writer, args []string) error {
+	fs := newFlagSet("tables")
+	positional, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	id, err := handle(positional[0])
+	if err != nil {
+		return err
+	}
+	defer id.FreeHandler()
+
+	tables, err := id.Tables()
+	if err != nil {
+		return err
+	}
+	for _, t := range tables {
+		fmt.Fprintln(out, t)
+	}
+	return nil
+}
+
+// describeCommand print column definitions of the table
+func describeCommand(out io.Writer, args []string) error {
+	fs := newFlagSet("describe")
+	positional, err := parseArgs(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	id, err := handle(positional[0])
+	if err != nil {
+		return err
+	}
+	defer id.FreeHandler()
+
+	columns, err := id.TableColumns(positional[1])
+	if err != nil {
+		return err
+	}
+	return describeColumns(out, columns)
+}
+
+// describeColumns print column definitions with generic SQL types
+func describeColumns(out io.Writer, columns []*common.Column) error {
+	mapper := common.GenericTypeMapper(true)
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTYPE\tNULLABLE\tKEY")
+	for _, c := range columns {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Name,
+			mapper.DataType(c.DataType, int(c.Length), int(c.Digits)),
+			yesNo(c.Nullable), yesNo(c.Key))
+	}
+	return tw.Flush()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// queryCommand query records and print them in the output format
+func queryCommand(out io.Writer, args []string) error {
+	fs := newFlagSet("query")
+	fields := fs.String("fields", "", "comma-separated field names, all fields if not given")
+	search := fs.String("search", "", "search condition")
+	order := fs.String("order", "", "comma-separated order fields like 'name:ASC,id:DESC'")
+	limit := fs.String("limit", "", "maximal number of records")
+	format := fs.String("format", "table", "output format 'table', 'csv', 'json', 'jsonl' or 'xml'")
+	null := fs.String("null", "", "text of NULL values in csv and xml output")
+	positional, err := parseArgs(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	var exportFormat common.ExportFormat
+	if *format != "table" {
+		exportFormat, err = common.ParseExportFormat(*format)
+		if err != nil {
+			return err
+		}
+	}
+	id, err := handle(positional[0])
+	if err != nil {
+		return err
+	}
+	defer id.FreeHandler()
+
+	query := &common.Query{TableName: positional[1], Fields: splitList(*fields),
+		Search: *search, Order: splitList(*order), Limit: *limit}
+	if len(query.Fields) == 0 {
+		query.Fields = []string{"*"}
+	}
+	if *format == "table" {
+		tw := newTableWriter(out)
+		_, err = id.Query(query, tw.write)
+		if err != nil {
+			return err
+		}
+		return tw.flush()
+	}
+	_, err = id.Export(query, exportFormat, out, &common.ExportOptions{NullValue: *null})
+	return err
+}
+
+// tableWriter write query records as aligned table
+type tableWriter struct {
+	tw      *tabwriter.Writer
+	started bool
+}
+
+func newTableWriter(out io.Writer) *tableWriter {
+	return &tableWriter{tw: tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)}
+}
+
+// write write record of the result, usable as result function
+func (tw *tableWriter) write(search *common.Query, result *common.Result) error {
+	if !tw.started {
+		tw.started = true
+		fmt.Fprintln(tw.tw, strings.Join(result.Fields, "\t"))
+	}
+	for i, v := range result.Rows {
+		if i > 0 {
+			fmt.Fprint(tw.tw, "\t")
+		}
+		fmt.Fprint(tw.tw, formatValue(v))
+	}
+	_, err := fmt.Fprintln(tw.tw)
+	return err
+}
+
+func (tw *tableWriter) flush() error {
+	return tw.tw.Flush()
+}
+
+// formatValue table cell text of the value
+func formatValue(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return fmt.Sprintf("<%d bytes>", len(value))
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	case string:
+		return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// execCommand execute SQL script of the file or standard input
+func execCommand(out io.Writer, args []string) error {
+	fs := newFlagSet("exec")
+	transaction := fs.Bool("transaction", false, "execute all statements in one transaction")
+	continueOnError := fs.Bool("continue", false, "continue with the next statement after an error, not in a transaction")
+	positional, err := parseArgs(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	var script []byte
+	if positional[1] == "-" {
+		script, err = io.ReadAll(os.Stdin)
+	} else {
+		script, err = os.ReadFile(positional[1])
+	}
+	if err != nil {
+		return err
+	}
+	id, err := handle(positional[0])
+	if err != nil {
+		return err
+	}
+	defer id.FreeHandler()
+
+	result, err := id.RunScript(string(script),
+		&common.ScriptOptions{Transaction: *transaction, ContinueOnError: *continueOnError})
+	if result != nil {
+		printScriptResult(out, result)
+	}
+	return err
+}
+
+// printScriptResult print the report of each executed statement
+func printScriptResult(out io.Writer, result *common.ScriptResult) {
+	for i, sr := range result.Statements {
+		statement, _, _ := strings.Cut(sr.Statement, "\n")
+		if len(statement) > 60 {
+			statement = statement[:57] + "..."
+		}
+		if sr.Err != nil {
+			fmt.Fprintf(out, "%d: %s failed after %v: %v\n", i+1, statement, sr.Duration, sr.Err)
+			continue
+		}
+		fmt.Fprintf(out, "%d: %s, %d rows affected in %v\n", i+1, statement, sr.RowsAffected, sr.Duration)
+	}
+	fmt.Fprintf(out, "Executed %d statements, %d failed\n", len(result.Statements), result.Failed)
+}
+
+// copyCommand copy table into another database
+func copyCommand(out io.Writer, args []string) error {
+	fs := newFlagSet("copy")
+	fields := fs.String("fields", "", "comma-separated source fields, all fields if not given")
+	search := fs.String("search", "", "search condition of source records")
+	key := fs.String("key", "", "source key field used to resume an interrupted copy")
+	resume := fs.Bool("resume", false, "resume after the highest key in the destination")
+	verify := fs.Bool("verify", false, "verify row count and checksum after copying")
+	batch := fs.Int("batch", flynn.DefaultCopyBatchSize, "number of records inserted in one batch")
+	positional, err := parseArgs(fs, args, 3, 4)
+	if err != nil {
+		return err
+	}
+	dstTable := positional[1]
+	if len(positional) > 3 {
+		dstTable = positional[3]
+	}
+	src, err := handle(positional[0])
+	if err != nil {
+		return err
+	}
+	defer src.FreeHandler()
+	dst, err := handle(positional[2])
+	if err != nil {
+		return err
+	}
+	defer dst.FreeHandler()
+
+	result, err := flynn.CopyTable(src, positional[1], dst, dstTable, &flynn.CopyOptions{
+		Fields: splitList(*fields), Search: *search, KeyField: *key,
+		Resume: *resume, Verify: *verify, BatchSize: *batch})
+	if result != nil {
+		fmt.Fprintf(out, "Copied %d records to %s, %d skipped\n", result.Copied, dstTable, result.Skipped)
+		if *verify && err == nil {
+			fmt.Fprintf(out, "Verified %d records, checksum %s\n", result.DestinationCount, result.DestinationChecksum)
+		}
+	}
+	return err
+}
+
+// streamCommand write LOB field content to the output
+func streamCommand(out io.Writer, args []string) error {
+	fs := newFlagSet("stream")
+	search := fs.String("search", "", "search condition selecting the record")
+	blocksize := fs.Int("blocksize", 0, "block size of the stream reads")
+	positional, err := parseArgs(fs, args, 3, 3)
+	if err != nil {
+		return err
+	}
+	id, err := handle(positional[0])
+	if err != nil {
+		return err
+	}
+	defer id.FreeHandler()
+
+	query := &common.Query{TableName: positional[1], Fields: []string{positional[2]},
+		Search: *search, Blocksize: int32(*blocksize)}
+	return id.Stream(query, func(search *common.Query, stream *common.Stream) error {
+		_, err := out.Write(stream.Data)
+		return err
+	})
+}
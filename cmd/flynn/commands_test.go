@@ -0,0 +1,87 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tknie/flynn/common"
+)
+
+func TestParseArgs(t *testing.T) {
+	fs := newFlagSet("query")
+	fs.SetOutput(io.Discard)
+	fields := fs.String("fields", "", "")
+	limit := fs.String("limit", "", "")
+	positional, err := parseArgs(fs, []string{"--limit", "5", "postgres://host/db", "albums", "--fields", "id, title,"}, 2, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"postgres://host/db", "albums"}, positional)
+	assert.Equal(t, "5", *limit)
+	assert.Equal(t, []string{"id", "title"}, splitList(*fields))
+
+	fs = newFlagSet("copy")
+	fs.SetOutput(io.Discard)
+	_, err = parseArgs(fs, []string{"a", "b"}, 3, 4)
+	assert.Equal(t, errUsage, err)
+	_, err = parseArgs(fs, []string{"a", "--unknown"}, 3, 4)
+	assert.Error(t, err)
+	assert.Equal(t, errUsage, queryCommand(io.Discard, []string{"--format", "csv", "url"}))
+	assert.EqualError(t, queryCommand(io.Discard, []string{"--format", "yaml", "url", "table"}),
+		"DB000058: export format yaml not valid")
+}
+
+func TestTableWriter(t *testing.T) {
+	var buffer bytes.Buffer
+	tw := newTableWriter(&buffer)
+	result := &common.Result{Fields: []string{"id", "name", "data", "created"}}
+	for _, rows := range [][]any{
+		{int64(1), "first\tline", []byte("abc"), time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)},
+		{int64(200), nil, nil, nil},
+	} {
+		result.Rows = rows
+		assert.NoError(t, tw.write(&common.Query{}, result))
+	}
+	assert.NoError(t, tw.flush())
+	assert.Equal(t, "id   name        data       created\n"+
+		"1    first line  <3 bytes>  2024-02-03T04:05:06Z\n"+
+		"200  NULL        NULL       NULL\n", buffer.String())
+}
+
+func TestDescribeColumns(t *testing.T) {
+	var buffer bytes.Buffer
+	err := describeColumns(&buffer, []*common.Column{
+		{Name: "id", DataType: common.Integer, Length: 8, Key: true},
+		{Name: "title", DataType: common.Alpha, Length: 255, Nullable: true},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "NAME   TYPE          NULLABLE  KEY\n"+
+		"id     INTEGER       no        yes\n"+
+		"title  VARCHAR(255)  yes       no\n", buffer.String())
+}
+
+func TestPrintScriptResult(t *testing.T) {
+	var buffer bytes.Buffer
+	printScriptResult(&buffer, &common.ScriptResult{Failed: 1, Statements: []*common.StatementResult{
+		{Statement: "UPDATE albums SET title = 'x'\nWHERE id = 1", RowsAffected: 1, Duration: 2 * time.Millisecond},
+		{Statement: "DROP TABLE pictures", RowsAffected: -1, Duration: time.Millisecond, Err: errors.New("table missing")},
+	}})
+	assert.Equal(t, "1: UPDATE albums SET title = 'x', 1 rows affected in 2ms\n"+
+		"2: DROP TABLE pictures failed after 1ms: table missing\n"+
+		"Executed 2 statements, 1 failed\n", buffer.String())
+}
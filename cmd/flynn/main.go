@@ -0,0 +1,162 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+// flynn is a command line client for databases accessed by the flynn
+// package.
+//
+//	flynn tables postgres://admin@host:5432/db
+//	flynn query postgres://admin@host:5432/db albums --fields id,title --format csv
+//	flynn stream postgres://admin@host:5432/db pictures media --search "id=1" > picture.jpg
+//
+// The password is taken out of the URL, the FLYNN_PASSWORD environment
+// variable or read from the terminal.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/tknie/flynn"
+	"github.com/tknie/flynn/common"
+)
+
+// passwordEnv environment variable containing the database password
+const passwordEnv = "FLYNN_PASSWORD"
+
+// errUsage command called with invalid arguments
+var errUsage = errors.New("invalid arguments")
+
+// command sub command of the command line client
+type command struct {
+	usage       string
+	description string
+	run         func(out io.Writer, args []string) error
+}
+
+var commands map[string]*command
+
+func init() {
+	commands = map[string]*command{
+		"tables":   {"<url>", "list tables or maps", tablesCommand},
+		"describe": {"<url> <table>", "describe columns of a table", describeCommand},
+		"query":    {"<url> <table> [flags]", "query records of a table", queryCommand},
+		"exec":     {"<url> <script>", "execute SQL script, '-' reads standard input", execCommand},
+		"copy":     {"<srcurl> <srctable> <dsturl> [dsttable] [flags]", "copy table into another database", copyCommand},
+		"stream":   {"<url> <table> <field> [flags]", "write LOB field to standard output", streamCommand},
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	c, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	err := c.run(os.Stdout, os.Args[2:])
+	switch {
+	case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// usage print usage of all commands
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: flynn <command> <arguments>")
+	fmt.Fprintln(os.Stderr)
+	for _, name := range []string{"tables", "describe", "query", "exec", "copy", "stream"} {
+		c := commands[name]
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", name, c.usage)
+		fmt.Fprintf(os.Stderr, "  %-8s   %s\n", "", c.description)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "The password is taken out of the URL, the "+passwordEnv+
+		" environment variable or read from the terminal.")
+}
+
+// newFlagSet flag set of the command printing the command usage
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: flynn %s %s\n", name, commands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs parse flags given before, between or after the positional
+// arguments and check the number of positional arguments
+func parseArgs(fs *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	positional := make([]string, 0)
+	for {
+		err := fs.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if len(positional) < min || len(positional) > max {
+		fs.Usage()
+		return nil, errUsage
+	}
+	return positional, nil
+}
+
+// splitList split comma-separated list ignoring empty entries
+func splitList(list string) []string {
+	entries := make([]string, 0)
+	for _, e := range strings.Split(list, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// handle register database handle of the URL. If the URL contains no
+// password it is taken out of the environment or read from the terminal.
+func handle(url string) (common.RegDbID, error) {
+	ref, password, err := common.NewReference(url)
+	if err != nil {
+		return 0, err
+	}
+	if password == "" {
+		password = os.Getenv(passwordEnv)
+	}
+	if password == "" && ref.User != "" && isTerminal(os.Stdin) {
+		password, err = readPassword(fmt.Sprintf("Password of %s: ", ref.User))
+		if err != nil {
+			return 0, err
+		}
+	}
+	return flynn.Handler(ref, password)
+}
+
+// isTerminal check if the file is a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,89 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// AdaptAction kind of schema change done by an adapt step
+type AdaptAction byte
+
+const (
+	AddColumn AdaptAction = iota
+	DropColumn
+	RenameColumn
+	AlterColumnType
+	AlterColumnNull
+	CreateIndex
+	DropIndex
+)
+
+var adaptActionNames = []string{"add column", "drop column", "rename column",
+	"alter type", "alter null", "create index", "drop index"}
+
+func (action AdaptAction) String() string {
+	return adaptActionNames[action]
+}
+
+// AdaptStep one schema change step of an adapt plan
+type AdaptStep struct {
+	Action      AdaptAction
+	Column      string
+	Statement   string
+	Destructive bool
+}
+
+// AdaptPlan plan of all schema changes needed to adapt a table to
+// a new Go structure. Destructive steps may loose data or reject
+// existing records and are only applied if explicitly requested.
+type AdaptPlan struct {
+	Table         string
+	Transactional bool
+	Steps         []*AdaptStep
+}
+
+// Empty no schema change is needed
+func (plan *AdaptPlan) Empty() bool {
+	return plan == nil || len(plan.Steps) == 0
+}
+
+// Destructive plan contains destructive steps
+func (plan *AdaptPlan) Destructive() bool {
+	if plan == nil {
+		return false
+	}
+	for _, s := range plan.Steps {
+		if s.Destructive {
+			return true
+		}
+	}
+	return false
+}
+
+// String dry run output of the adapt plan
+func (plan *AdaptPlan) String() string {
+	if plan.Empty() {
+		return "-- no changes needed\n"
+	}
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf("-- adapt table %s (%d steps, transactional=%v)\n",
+		plan.Table, len(plan.Steps), plan.Transactional))
+	for _, s := range plan.Steps {
+		if s.Destructive {
+			buffer.WriteString("-- destructive: " + s.Action.String() + " " + s.Column + "\n")
+		}
+		buffer.WriteString(s.Statement + ";\n")
+	}
+	return buffer.String()
+}
@@ -0,0 +1,288 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/tknie/errorrepo"
+	"github.com/tknie/log"
+)
+
+const (
+	// AutoCreateOption tag option of a timestamp set on insert, like
+	// `flynn:"created:autocreate"`
+	AutoCreateOption = "autocreate"
+	// AutoUpdateOption tag option of a timestamp set on insert and update,
+	// like `flynn:"modified:autoupdate"`
+	AutoUpdateOption = "autoupdate"
+	// VersionOption tag option of a version field used for optimistic
+	// locking, like `flynn:"version:version"`
+	VersionOption = "version"
+	// SoftDeleteOption tag option of a timestamp field set on delete
+	// instead of removing the record, like `flynn:"deleted_at:softdelete"`
+	SoftDeleteOption = "softdelete"
+)
+
+const versionConflictError = "DB000047"
+
+var autoOptions = []string{AutoCreateOption, AutoUpdateOption, VersionOption}
+
+// fieldOptions all tag options of fields handled automatically
+var fieldOptions = append(autoOptions, SoftDeleteOption)
+
+// UpdateVersion version of a record updated with optimistic locking
+type UpdateVersion struct {
+	Field string
+	Old   int64
+	value reflect.Value
+}
+
+// Reset restore old version in the structure after failed update
+func (uv *UpdateVersion) Reset() {
+	if uv == nil {
+		return
+	}
+	setVersion(uv.value, uv.Old)
+}
+
+// ResetVersions restore old versions of all records of a failed update
+func ResetVersions(versions []*UpdateVersion) {
+	for _, uv := range versions {
+		uv.Reset()
+	}
+}
+
+// VersionConflict error returned if the record of the update was changed
+// in the meantime
+func VersionConflict(table string, version int64) error {
+	return errorrepo.NewError(versionConflictError, table, version)
+}
+
+// IsVersionConflict check if the error is an optimistic locking conflict
+func IsVersionConflict(err error) bool {
+	var e *errorrepo.Error
+	return errors.As(err, &e) && e.ID() == versionConflictError
+}
+
+// AutoFields field names of automatic fields with the given tag option
+func (dynamic *typeInterface) AutoFields(option string) []string {
+	return dynamic.RowNames["#"+option]
+}
+
+// ExcludeFields remove the fields from the field list of the dynamic
+// interface
+func (dynamic *typeInterface) ExcludeFields(fields ...string) {
+	if len(fields) == 0 {
+		return
+	}
+	if dynamic.Excluded == nil {
+		dynamic.Excluded = make(map[string]void)
+	}
+	for _, f := range fields {
+		dynamic.Excluded[strings.ToLower(f)] = member
+	}
+	rowFields := make([]string, 0, len(dynamic.RowFields))
+	for _, f := range dynamic.RowFields {
+		if _, ok := dynamic.Excluded[strings.ToLower(f)]; !ok {
+			rowFields = append(rowFields, f)
+		}
+	}
+	dynamic.RowFields = rowFields
+}
+
+// SoftDeleteColumn column of the soft delete field of the entries
+// structure, empty if records are deleted physically
+func (entries *Entries) SoftDeleteColumn() string {
+	if entries.DataStruct == nil || entries.HardDelete {
+		return ""
+	}
+	dynamic := CreateInterfaceNaming(entries.DataStruct, []string{"*"}, entries.Naming)
+	if fields := dynamic.AutoFields(SoftDeleteOption); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// CreateUpdateInterface create dynamic interface of the update entries,
+// fields set on creation only are not updated
+func CreateUpdateInterface(updateInfo *Entries) *typeInterface {
+	dynamic := CreateInterfaceNaming(updateInfo.DataStruct, updateInfo.Fields, updateInfo.Naming)
+	dynamic.ExcludeFields(dynamic.AutoFields(AutoCreateOption)...)
+	return dynamic
+}
+
+// PrepareInsert set automatic timestamps and initial version of the
+// structure value before insert. Values given by copy are copied into a new
+// pointer returned to be used for the insert.
+func (dynamic *typeInterface) PrepareInsert(value any) (any, error) {
+	if !dynamic.hasAutoFields() {
+		return value, nil
+	}
+	value, v := addressable(value)
+	now := time.Now()
+	for _, name := range dynamic.AutoFields(AutoCreateOption) {
+		if err := dynamic.setTimestamp(v, name, now, true); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range dynamic.AutoFields(AutoUpdateOption) {
+		if err := dynamic.setTimestamp(v, name, now, false); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range dynamic.AutoFields(VersionOption) {
+		f, err := dynamic.versionField(v, name)
+		if err != nil {
+			return nil, err
+		}
+		if f.IsZero() {
+			setVersion(f, 1)
+		}
+	}
+	return value, nil
+}
+
+// PrepareUpdate set automatic update timestamps and increment the version
+// of the structure value before update. The version returned is used for
+// optimistic locking, it is nil if the structure has no version field.
+func (dynamic *typeInterface) PrepareUpdate(value any) (any, *UpdateVersion, error) {
+	if !dynamic.hasAutoFields() {
+		return value, nil, nil
+	}
+	value, v := addressable(value)
+	now := time.Now()
+	for _, name := range dynamic.AutoFields(AutoUpdateOption) {
+		if err := dynamic.setTimestamp(v, name, now, false); err != nil {
+			return nil, nil, err
+		}
+	}
+	var uv *UpdateVersion
+	for _, name := range dynamic.AutoFields(VersionOption) {
+		f, err := dynamic.versionField(v, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		uv = &UpdateVersion{Field: name, Old: versionOf(f), value: f}
+		setVersion(f, uv.Old+1)
+		log.Log.Debugf("Update version %s from %d", name, uv.Old)
+	}
+	return value, uv, nil
+}
+
+// hasAutoFields check if any automatic field is part of the field list
+func (dynamic *typeInterface) hasAutoFields() bool {
+	for _, o := range autoOptions {
+		if len(dynamic.AutoFields(o)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// addressable pointer to the structure value, values given by copy are
+// copied into a new instance
+func addressable(value any) (any, reflect.Value) {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Pointer {
+		return value, v.Elem()
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	return p.Interface(), p.Elem()
+}
+
+// setTimestamp set timestamp field to the given time, if onlyZero is set
+// timestamps already set are kept
+func (dynamic *typeInterface) setTimestamp(v reflect.Value, name string, now time.Time, onlyZero bool) error {
+	f, ok := dynamic.fieldByColumn(v, "", name)
+	if !ok {
+		return nil
+	}
+	switch {
+	case f.Type() == timeType:
+		if !onlyZero || f.Interface().(time.Time).IsZero() {
+			f.Set(reflect.ValueOf(now))
+		}
+	case f.Type() == reflect.PointerTo(timeType):
+		if !onlyZero || f.IsNil() || f.Elem().Interface().(time.Time).IsZero() {
+			f.Set(reflect.ValueOf(&now))
+		}
+	default:
+		return errorrepo.NewError("DB000048", name, f.Type().String())
+	}
+	return nil
+}
+
+// versionField field of the version column, only integer fields are valid
+func (dynamic *typeInterface) versionField(v reflect.Value, name string) (reflect.Value, error) {
+	f, ok := dynamic.fieldByColumn(v, "", name)
+	if !ok {
+		return reflect.Value{}, errorrepo.NewError("DB000048", name, v.Type().String())
+	}
+	if !isNumberKind(f.Kind()) || f.Kind() == reflect.Float32 || f.Kind() == reflect.Float64 {
+		return reflect.Value{}, errorrepo.NewError("DB000048", name, f.Type().String())
+	}
+	return f, nil
+}
+
+// versionOf current version of the version field
+func versionOf(f reflect.Value) int64 {
+	if f.CanInt() {
+		return f.Int()
+	}
+	return int64(f.Uint())
+}
+
+// setVersion set version of the version field
+func setVersion(f reflect.Value, version int64) {
+	if !f.IsValid() {
+		return
+	}
+	if f.CanInt() {
+		f.SetInt(version)
+		return
+	}
+	f.SetUint(uint64(version))
+}
+
+// fieldByColumn search field of the column name in the structure value
+// including flattened nested structures
+func (dynamic *typeInterface) fieldByColumn(v reflect.Value, prefix, name string) (reflect.Value, bool) {
+	for fi, fm := range structMetadata(v.Type()) {
+		if fm.tagInfo == IgnoreTag {
+			continue
+		}
+		cv := v.Field(fi)
+		if strings.EqualFold(fm.columnName(dynamic.Naming, prefix), name) {
+			return cv, true
+		}
+		if fm.tagInfo != NormalTag || fm.hasConverter || fm.lazy {
+			continue
+		}
+		if cv.Kind() == reflect.Pointer {
+			if cv.IsNil() {
+				continue
+			}
+			cv = cv.Elem()
+		}
+		if cv.Kind() == reflect.Struct && cv.Type() != timeType && !isNullType(cv.Type()) {
+			if f, ok := dynamic.fieldByColumn(cv, prefix+StructPrefix(fm.field, dynamic.Naming), name); ok {
+				return f, true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
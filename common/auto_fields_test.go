@@ -0,0 +1,106 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type autoRecord struct {
+	ID       int
+	Name     string
+	Created  time.Time  `flynn:"created:autocreate"`
+	Modified *time.Time `flynn:"modified:autoupdate"`
+	Version  int64      `flynn:"version:version"`
+}
+
+func TestAutoFieldsInsert(t *testing.T) {
+	InitLog(t)
+
+	v := &autoRecord{ID: 1, Name: "abc"}
+	ti := CreateInterface(v, []string{"*"})
+	assert.Equal(t, []string{"created"}, ti.AutoFields(AutoCreateOption))
+	assert.Equal(t, []string{"modified"}, ti.AutoFields(AutoUpdateOption))
+	assert.Equal(t, []string{"version"}, ti.AutoFields(VersionOption))
+
+	value, err := ti.PrepareInsert(v)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Same(t, v, value)
+	assert.False(t, v.Created.IsZero())
+	assert.NotNil(t, v.Modified)
+	assert.Equal(t, int64(1), v.Version)
+	values, err := ti.CreateValues(value)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), values[4])
+
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	record := autoRecord{ID: 2, Created: created, Version: 5}
+	value, err = ti.PrepareInsert(record)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, created, value.(*autoRecord).Created)
+	assert.Equal(t, int64(5), value.(*autoRecord).Version)
+	assert.Nil(t, record.Modified)
+
+	ti = CreateInterface(v, []string{"ID", "Name"})
+	value, err = ti.PrepareInsert(record)
+	assert.NoError(t, err)
+	assert.Equal(t, record, value)
+}
+
+func TestAutoFieldsUpdate(t *testing.T) {
+	InitLog(t)
+
+	v := &autoRecord{ID: 1, Name: "abc", Version: 3}
+	ti := CreateUpdateInterface(&Entries{DataStruct: v, Fields: []string{"*"}})
+	assert.Equal(t, []string{"ID", "Name", "modified", "version"}, ti.RowFields)
+	value, version, err := ti.PrepareUpdate(v)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, &UpdateVersion{Field: "version", Old: 3, value: version.value}, version)
+	assert.Equal(t, int64(4), v.Version)
+	assert.NotNil(t, v.Modified)
+	values, err := ti.CreateValues(value)
+	assert.NoError(t, err)
+	assert.Len(t, values, 4)
+	assert.Equal(t, int64(4), values[3])
+	version.Reset()
+	assert.Equal(t, int64(3), v.Version)
+	ResetVersions([]*UpdateVersion{nil})
+
+	err = VersionConflict("records", 3)
+	assert.True(t, IsVersionConflict(err))
+	assert.True(t, IsVersionConflict(fmt.Errorf("wrapped: %w", err)))
+	assert.False(t, IsVersionConflict(fmt.Errorf("other")))
+}
+
+func TestAutoFieldsInvalid(t *testing.T) {
+	InitLog(t)
+
+	v := &struct {
+		Created string  `flynn:"created:autocreate"`
+		Version float64 `flynn:"version:version"`
+	}{}
+	ti := CreateInterface(v, []string{"*"})
+	_, err := ti.PrepareInsert(v)
+	assert.Error(t, err)
+	_, _, err = ti.PrepareUpdate(v)
+	assert.Error(t, err)
+}
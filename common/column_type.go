@@ -0,0 +1,218 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tknie/errorrepo"
+	"github.com/tknie/log"
+)
+
+// ColumnType scan target and result conversion of a database column type
+type ColumnType struct {
+	// ScanTarget create new scan target, nullable columns may return NULL
+	ScanTarget func(nullable bool) any
+	// Convert convert scan target into result value, if not set the
+	// scan target pointer is dereferenced
+	Convert func(target any) (any, error)
+}
+
+var columnTypesLock sync.RWMutex
+
+var columnTypes = make(map[string]*ColumnType)
+
+// RegisterColumnType register or replace scan target and conversion
+// of a database type name
+func RegisterColumnType(databaseTypeName string, columnType *ColumnType) {
+	columnTypesLock.Lock()
+	defer columnTypesLock.Unlock()
+	columnTypes[strings.ToUpper(databaseTypeName)] = columnType
+}
+
+// LookupColumnType search column type definition of database type name,
+// type parameters like 'NUMERIC(10,2)' are ignored
+func LookupColumnType(databaseTypeName string) (*ColumnType, bool) {
+	name := strings.ToUpper(strings.TrimSpace(databaseTypeName))
+	columnTypesLock.RLock()
+	defer columnTypesLock.RUnlock()
+	if ct, ok := columnTypes[name]; ok {
+		return ct, true
+	}
+	if i := strings.IndexByte(name, '('); i > 0 {
+		if ct, ok := columnTypes[strings.TrimSpace(name[:i])]; ok {
+			return ct, true
+		}
+	}
+	return nil, false
+}
+
+// decimalTextType registry name of exact decimal numbers scanned as text
+const decimalTextType = "DECIMAL TEXT"
+
+// rawColumnType fallback of unknown database types, the driver value
+// is returned as bytes or string
+var rawColumnType = &ColumnType{
+	ScanTarget: func(nullable bool) any { return new(any) },
+	Convert:    convertRaw,
+}
+
+func init() {
+	register := func(ct *ColumnType, names ...string) {
+		for _, n := range names {
+			RegisterColumnType(n, ct)
+		}
+	}
+	register(&ColumnType{ScanTarget: func(nullable bool) any { return &sql.NullString{} }},
+		"VARCHAR", "TEXT", "UNICODE")
+	register(&ColumnType{ScanTarget: nullableTarget(&sql.NullString{}, "")},
+		"BPCHAR", "CHAR", "NCHAR", "CHARACTER", "NVARCHAR", "VARCHAR2", "NVARCHAR2",
+		"NAME", "CITEXT", "TINYTEXT", "MEDIUMTEXT", "LONGTEXT", "ENUM", "SET",
+		"CLOB", "NCLOB", "LONG", "ROWID", "UROWID", "UUID", "JSON", "JSONB", "XML",
+		"TIME", "TIMETZ", "INTERVAL", "YEAR", "MONEY", "INET", "CIDR", "MACADDR",
+		"VARBIT", "TSVECTOR")
+	register(&ColumnType{ScanTarget: nullableTarget(&sql.NullInt32{}, int32(0))},
+		"NUMBER", "INT4", "INTEGER", "INT", "INT2", "SMALLINT", "TINYINT",
+		"MEDIUMINT", "SERIAL", "SMALLSERIAL")
+	register(&ColumnType{ScanTarget: nullableTarget(&sql.NullInt64{}, int64(0))},
+		"BIGINT", "INT8", "BIGSERIAL", "OID")
+	register(&ColumnType{ScanTarget: nullableTarget(&NullUint{}, uint64(0))},
+		"UNSIGNED INT", "UNSIGNED BIGINT", "UNSIGNED SMALLINT", "UNSIGNED TINYINT",
+		"UNSIGNED MEDIUMINT")
+	register(&ColumnType{ScanTarget: nullableTarget(&sql.NullString{}, "")},
+		decimalTextType)
+	register(&ColumnType{ScanTarget: nullableTarget(&sql.NullFloat64{}, float64(0))},
+		"DECIMAL", "NUMERIC", "FLOAT", "FLOAT4", "FLOAT8", "REAL", "DOUBLE",
+		"DOUBLE PRECISION", "BINARY_FLOAT", "BINARY_DOUBLE")
+	register(&ColumnType{ScanTarget: nullableTarget(&sql.NullBool{}, false)},
+		"BOOL", "BOOLEAN")
+	register(&ColumnType{ScanTarget: nullableTarget(&sql.NullByte{}, byte(0))},
+		"BIT")
+	register(&ColumnType{ScanTarget: nullableTarget(&NullBytes{}, []byte{})},
+		"BLOB", "BINARY", "BYTEA", "DATA", "VARBINARY", "RAW", "LONG RAW",
+		"TINYBLOB", "MEDIUMBLOB", "LONGBLOB")
+	register(&ColumnType{ScanTarget: nullableTarget(&sql.NullTime{}, time.Time{})},
+		"TIMESTAMP", "TIMESTAMPTZ", "DATE", "DATETIME",
+		"TIMESTAMP WITH TIME ZONE", "TIMESTAMP WITH LOCAL TIME ZONE")
+}
+
+// nullableTarget scan target function creating new null type instance
+// for nullable columns and a value instance otherwise
+func nullableTarget(nullType any, value any) func(nullable bool) any {
+	nt := reflect.TypeOf(nullType).Elem()
+	vt := reflect.TypeOf(value)
+	return func(nullable bool) any {
+		if nullable {
+			return reflect.New(nt).Interface()
+		}
+		return reflect.New(vt).Interface()
+	}
+}
+
+// columnTypeName database type name used for registry lookup, decimal
+// numbers are mapped by their precision
+func columnTypeName(t *sql.ColumnType) string {
+	precision, scale, ok := t.DecimalSize()
+	return numericTypeName(strings.ToUpper(t.DatabaseTypeName()), precision, scale, ok)
+}
+
+// numericTypeName registry name of decimal number types depending on the
+// precision. Integers up to 18 digits are scanned as integer, larger or
+// scaled numbers as text to keep all digits.
+func numericTypeName(name string, precision, scale int64, ok bool) string {
+	switch name {
+	case "NUMBER", "NUMERIC", "DECIMAL":
+	default:
+		return name
+	}
+	switch {
+	case !ok:
+		return name
+	case scale == 0 && precision > 0 && precision <= 9:
+		return "INTEGER"
+	case scale == 0 && precision > 0 && precision <= 18:
+		return "BIGINT"
+	}
+	return decimalTextType
+}
+
+// columnTypeOf registered column type of the column or the raw fallback
+func columnTypeOf(t *sql.ColumnType) *ColumnType {
+	if ct, ok := LookupColumnType(columnTypeName(t)); ok {
+		return ct
+	}
+	log.Log.Debugf("Type %s of column %s not registered, use raw value", t.DatabaseTypeName(), t.Name())
+	return rawColumnType
+}
+
+// ConvertTypeData convert values scanned into targets created by
+// CreateTypeData into result values
+func ConvertTypeData(ct []*sql.ColumnType, data []interface{}) ([]interface{}, error) {
+	result := make([]interface{}, len(data))
+	for i, d := range data {
+		var convert func(target any) (any, error)
+		if i < len(ct) {
+			convert = columnTypeOf(ct[i]).Convert
+		}
+		if convert == nil {
+			convert = dereference
+		}
+		v, err := convert(d)
+		if err != nil {
+			name, typeName := "", ""
+			if i < len(ct) {
+				name, typeName = ct[i].Name(), ct[i].DatabaseTypeName()
+			}
+			return nil, errorrepo.NewError("DB000043", name, typeName, err)
+		}
+		result[i] = v
+	}
+	return result, nil
+}
+
+// dereference return value the scan target points to
+func dereference(target any) (any, error) {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer {
+		return target, nil
+	}
+	if v.IsNil() {
+		return nil, nil
+	}
+	return v.Elem().Interface(), nil
+}
+
+// convertRaw convert driver value of unknown type into bytes or string
+func convertRaw(target any) (any, error) {
+	v, err := dereference(target)
+	if err != nil {
+		return nil, err
+	}
+	switch r := v.(type) {
+	case nil, []byte, string:
+		return r, nil
+	case int64, float64, bool, time.Time:
+		return r, nil
+	case driver.Valuer:
+		return r.Value()
+	case fmt.Stringer:
+		return r.String(), nil
+	default:
+		return nil, fmt.Errorf("unsupported raw value type %T", v)
+	}
+}
@@ -0,0 +1,125 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testUUID [4]byte
+
+func (u testUUID) String() string {
+	return "uuid-0102"
+}
+
+func TestColumnTypeLookup(t *testing.T) {
+	InitLog(t)
+
+	for _, name := range []string{"UUID", "jsonb", "NUMERIC", "FLOAT8", "DATE", "BOOL",
+		"VARCHAR2", "NUMERIC(10,2)", "BINARY_DOUBLE", "DATETIME", "LONGBLOB"} {
+		_, ok := LookupColumnType(name)
+		assert.True(t, ok, name)
+	}
+	_, ok := LookupColumnType("_TEXT")
+	assert.False(t, ok)
+
+	ct, _ := LookupColumnType("FLOAT8")
+	assert.IsType(t, &sql.NullFloat64{}, ct.ScanTarget(true))
+	assert.IsType(t, new(float64), ct.ScanTarget(false))
+	ct, _ = LookupColumnType("DATE")
+	assert.IsType(t, &sql.NullTime{}, ct.ScanTarget(true))
+	assert.IsType(t, &time.Time{}, ct.ScanTarget(false))
+	ct, _ = LookupColumnType("VARCHAR")
+	assert.IsType(t, &sql.NullString{}, ct.ScanTarget(false))
+}
+
+func TestColumnTypeNumeric(t *testing.T) {
+	InitLog(t)
+
+	assert.Equal(t, "INTEGER", numericTypeName("NUMBER", 9, 0, true))
+	assert.Equal(t, "BIGINT", numericTypeName("NUMBER", 18, 0, true))
+	assert.Equal(t, decimalTextType, numericTypeName("NUMBER", 19, 0, true))
+	assert.Equal(t, decimalTextType, numericTypeName("NUMERIC", 20, 0, true))
+	assert.Equal(t, decimalTextType, numericTypeName("DECIMAL", 10, 2, true))
+	assert.Equal(t, decimalTextType, numericTypeName("NUMERIC", 0, 0, true))
+	assert.Equal(t, "NUMBER", numericTypeName("NUMBER", 0, 0, false))
+	assert.Equal(t, "FLOAT8", numericTypeName("FLOAT8", 53, 0, true))
+
+	// NUMBER(19) created for int64 contains values above 2^31
+	ct, _ := LookupColumnType(numericTypeName("NUMBER", 19, 0, true))
+	assert.IsType(t, new(string), ct.ScanTarget(false))
+	target := ct.ScanTarget(true)
+	assert.NoError(t, target.(sql.Scanner).Scan("3000000000"))
+	data, err := ConvertTypeData(nil, []any{target})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{sql.NullString{String: "3000000000", Valid: true}}, data)
+
+	ct, _ = LookupColumnType(numericTypeName("NUMBER", 18, 0, true))
+	assert.IsType(t, new(int64), ct.ScanTarget(false))
+	target = ct.ScanTarget(true)
+	assert.NoError(t, target.(sql.Scanner).Scan("3000000000"))
+	assert.Equal(t, sql.NullInt64{Int64: 3000000000, Valid: true}, *(target.(*sql.NullInt64)))
+
+	// NUMERIC(20) created for uint64 keeps all digits
+	ct, _ = LookupColumnType(numericTypeName("NUMERIC", 20, 0, true))
+	target = ct.ScanTarget(true)
+	assert.NoError(t, target.(sql.Scanner).Scan([]byte("18446744073709551615")))
+	assert.Equal(t, "18446744073709551615", target.(*sql.NullString).String)
+}
+
+func TestColumnTypeRegister(t *testing.T) {
+	InitLog(t)
+
+	RegisterColumnType("test_point", &ColumnType{
+		ScanTarget: func(nullable bool) any { return new(string) },
+		Convert: func(target any) (any, error) {
+			return "point" + *(target.(*string)), nil
+		},
+	})
+	ct, ok := LookupColumnType("TEST_POINT")
+	if !assert.True(t, ok) {
+		return
+	}
+	target := ct.ScanTarget(false)
+	*(target.(*string)) = "(1,2)"
+	v, err := ct.Convert(target)
+	assert.NoError(t, err)
+	assert.Equal(t, "point(1,2)", v)
+}
+
+func TestColumnTypeConvert(t *testing.T) {
+	InitLog(t)
+
+	i := int32(42)
+	data, err := ConvertTypeData(nil, []any{&i, &sql.NullString{String: "abc", Valid: true},
+		&sql.NullInt64{}})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{int32(42), sql.NullString{String: "abc", Valid: true}, sql.NullInt64{}}, data)
+
+	for _, raw := range []any{[]byte{1, 2}, "text[]", nil, int64(3)} {
+		r := raw
+		v, err := convertRaw(&r)
+		assert.NoError(t, err)
+		assert.Equal(t, raw, v)
+	}
+	var r any = testUUID{1, 2}
+	v, err := convertRaw(&r)
+	assert.NoError(t, err)
+	assert.Equal(t, "uuid-0102", v)
+	r = struct{ a int }{1}
+	_, err = convertRaw(&r)
+	assert.Error(t, err)
+}
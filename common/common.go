@@ -12,8 +12,11 @@
 package common
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"reflect"
 	"strings"
 	"time"
 
@@ -32,9 +35,10 @@ const (
 	JSONTag
 	IndexTag
 	KeyTag
+	ConvTag
 )
 
-var tagInfoNames = []string{"Normal", "Ignore", "Sub", "YAML", "XML", "JSON", "Index", "Key"}
+var tagInfoNames = []string{"Normal", "Ignore", "Sub", "YAML", "XML", "JSON", "Index", "Key", "Conv"}
 
 func (tagInfo TagInfo) String() string {
 	return tagInfoNames[tagInfo] + " Tag"
@@ -66,6 +70,55 @@ func TagInfoParse(info string) (string, TagInfo) {
 	return infoSplit[0], NormalTag
 }
 
+// tagKeywords options in tag info which are given without value
+var tagKeywords = []string{"index", AutoCreateOption, AutoUpdateOption, VersionOption, SoftDeleteOption}
+
+// IsTagOption check if tag info part is an option like 'rename=old' or
+// an option keyword like 'index'
+func IsTagOption(part string) bool {
+	if strings.Contains(part, "=") {
+		return true
+	}
+	for _, k := range tagKeywords {
+		if strings.EqualFold(part, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// TagOption search option in tag info like 'name:option=value' and returns
+// the option value. Option keywords are returned with empty value.
+func TagOption(info, option string) (string, bool) {
+	infoSplit := strings.Split(info, ":")
+	for _, part := range infoSplit[1:] {
+		key, value, _ := strings.Cut(part, "=")
+		if strings.EqualFold(key, option) && IsTagOption(part) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// StructPrefix column name prefix of fields of a flattened nested
+// structure. Fields of anonymous embedded structures are promoted without
+// prefix. Named structures use the tag option 'prefix=addr_' or the column
+// name of the field followed by '_'.
+func StructPrefix(sf reflect.StructField, naming NamingStrategy) string {
+	if sf.Anonymous {
+		return ""
+	}
+	tag := sf.Tag.Get(TagName)
+	if prefix, ok := TagOption(tag, "prefix"); ok {
+		return prefix
+	}
+	name, _ := TagInfoParse(tag)
+	if name == "" {
+		name = naming.ColumnName(sf.Name)
+	}
+	return name + "_"
+}
+
 type CreateStatus byte
 
 const (
@@ -97,19 +150,29 @@ type Entries struct {
 	Values     [][]any
 	Returning  []string
 	Criteria   string
+	// Naming naming strategy of field names, the strategy of the
+	// database handle is used if not set
+	Naming NamingStrategy
+	// HardDelete delete records physically even if the structure
+	// contains a soft delete field
+	HardDelete bool
 }
 
 type Database interface {
 	Used()
 	ID() RegDbID
 	URL() string
+	DriverType() ReferenceType
 	Ping() error
 	SetCredentials(string, string) error
 	Maps() ([]string, error)
 	Clone() Database
 	GetTableColumn(tableName string) ([]string, error)
+	TableColumns(tableName string) ([]*Column, error)
 	CreateTable(string, any) error
 	AdaptTable(string, any) error
+	PlanAdaptTable(string, any) (*AdaptPlan, error)
+	ApplyAdaptPlan(*AdaptPlan, bool) error
 	DeleteTable(string) error
 	Open() (any, error)
 	Close()
@@ -118,6 +181,7 @@ type Database interface {
 	Update(name string, insert *Entries) ([][]any, int64, error)
 	Delete(name string, remove *Entries) (int64, error)
 	Batch(batch string) error
+	Execute(statement string, args ...any) (int64, error)
 	BatchSelect(batch string) ([][]interface{}, error)
 	BatchSelectFct(search *Query, f ResultFunction) error
 	Query(search *Query, f ResultFunction) (*Result, error)
@@ -125,13 +189,18 @@ type Database interface {
 	Commit() error
 	Rollback() error
 	Stream(search *Query, sf StreamFunction) error
+	StreamWrite(search *Query, reader io.Reader) (int64, error)
+	OpenLOB(search *Query) (LOBReader, error)
 }
 
+// Column column definition. The length of integer columns is the byte width.
 type Column struct {
 	Name       string
 	DataType   DataType
 	Length     uint16
 	Digits     uint8
+	Nullable   bool
+	Key        bool
 	SubColumns []*Column
 }
 
@@ -189,7 +258,10 @@ func (id RegDbID) Query(query *Query, f ResultFunction) (*Result, error) {
 		return nil, err
 	}
 	log.Log.Debugf("Driver %T", driver)
-	return driver.Query(query, f)
+	if query.Naming == nil {
+		query.Naming = id.NamingStrategy()
+	}
+	return driver.Query(query, afterQueryFunction(context.Background(), id, f))
 }
 
 // CreateTable create a new table
@@ -201,7 +273,8 @@ func (id RegDbID) CreateTable(tableName string, columns any) error {
 	return driver.CreateTable(tableName, columns)
 }
 
-// AdaptTable create a new table
+// AdaptTable adapt table to new structure applying all non-destructive
+// schema changes
 func (id RegDbID) AdaptTable(tableName string, columns any) error {
 	driver, err := searchDataDriver(id)
 	if err != nil {
@@ -210,6 +283,26 @@ func (id RegDbID) AdaptTable(tableName string, columns any) error {
 	return driver.AdaptTable(tableName, columns)
 }
 
+// PlanAdaptTable generate plan of all schema changes needed to adapt
+// the table to the given structure. The plan is not applied.
+func (id RegDbID) PlanAdaptTable(tableName string, newStruct any) (*AdaptPlan, error) {
+	driver, err := searchDataDriver(id)
+	if err != nil {
+		return nil, err
+	}
+	return driver.PlanAdaptTable(tableName, newStruct)
+}
+
+// ApplyAdaptPlan apply adapt plan to the database. Destructive steps are
+// skipped if destructive is not set.
+func (id RegDbID) ApplyAdaptPlan(plan *AdaptPlan, destructive bool) error {
+	driver, err := searchDataDriver(id)
+	if err != nil {
+		return err
+	}
+	return driver.ApplyAdaptPlan(plan, destructive)
+}
+
 // CreateTableIfNotExists create a new table if not exists
 func (id RegDbID) CreateTableIfNotExists(tableName string, columns any) (CreateStatus, error) {
 	driver, err := searchDataDriver(id)
@@ -224,7 +317,7 @@ func (id RegDbID) CreateTableIfNotExists(tableName string, columns any) (CreateS
 		return CreateError, err
 	}
 	for _, d := range dbTables {
-		if d == tableName {
+		if strings.EqualFold(d, tableName) {
 			return CreateExists, nil
 		}
 	}
@@ -257,6 +350,16 @@ func (id RegDbID) Batch(batch string) error {
 	return driver.Batch(batch)
 }
 
+// Execute execute SQL statement with parameters. The statement is
+// part of the current transaction if one is started.
+func (id RegDbID) Execute(statement string, args ...any) (int64, error) {
+	driver, err := searchDataDriver(id)
+	if err != nil {
+		return -1, err
+	}
+	return driver.Execute(statement, args...)
+}
+
 // BatchSelect batch SQL query in table
 func (id RegDbID) BatchSelect(batch string) ([][]interface{}, error) {
 	driver, err := searchDataDriver(id)
@@ -272,7 +375,10 @@ func (id RegDbID) BatchSelectFct(batch *Query, f ResultFunction) error {
 	if err != nil {
 		return err
 	}
-	return driver.BatchSelectFct(batch, f)
+	if batch.Naming == nil {
+		batch.Naming = id.NamingStrategy()
+	}
+	return driver.BatchSelectFct(batch, afterQueryFunction(context.Background(), id, f))
 }
 
 // Open open the database connection
@@ -314,7 +420,21 @@ func (id RegDbID) Insert(name string, insert *Entries) ([][]any, error) {
 		log.Log.Fatal("ID mismatch")
 	}
 	log.Log.Debugf("Driver %d == %d-> %p", id, driver.ID(), driver)
-	return driver.Insert(name, insert)
+	if insert.Naming == nil {
+		insert.Naming = id.NamingStrategy()
+	}
+	ctx := context.Background()
+	values := insert.structValues()
+	err = callHooks(values, func(h BeforeInsertHook) error { return h.BeforeInsert(ctx, id) })
+	if err != nil {
+		return nil, err
+	}
+	returning, err := driver.Insert(name, insert)
+	if err != nil {
+		return nil, err
+	}
+	err = callHooks(values, func(h AfterInsertHook) error { return h.AfterInsert(ctx, id) })
+	return returning, err
 }
 
 // Update update record in table
@@ -323,7 +443,21 @@ func (id RegDbID) Update(name string, insert *Entries) ([][]any, int64, error) {
 	if err != nil {
 		return nil, 0, err
 	}
-	return driver.Update(name, insert)
+	if insert.Naming == nil {
+		insert.Naming = id.NamingStrategy()
+	}
+	ctx := context.Background()
+	values := insert.structValues()
+	err = callHooks(values, func(h BeforeUpdateHook) error { return h.BeforeUpdate(ctx, id) })
+	if err != nil {
+		return nil, 0, err
+	}
+	returning, rowsAffected, err := driver.Update(name, insert)
+	if err != nil {
+		return nil, rowsAffected, err
+	}
+	err = callHooks(values, func(h AfterUpdateHook) error { return h.AfterUpdate(ctx, id) })
+	return returning, rowsAffected, err
 }
 
 // Delete Delete database records
@@ -332,9 +466,26 @@ func (id RegDbID) Delete(name string, remove *Entries) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
+	if remove.Naming == nil {
+		remove.Naming = id.NamingStrategy()
+	}
+	err = callHooks([]any{remove.DataStruct}, func(h BeforeDeleteHook) error {
+		return h.BeforeDelete(context.Background(), id)
+	})
+	if err != nil {
+		return 0, err
+	}
 	return driver.Delete(name, remove)
 }
 
+// Purge delete records physically, soft delete fields of the structure
+// are ignored
+func (id RegDbID) Purge(name string, remove *Entries) (int64, error) {
+	purge := *remove
+	purge.HardDelete = true
+	return id.Delete(name, &purge)
+}
+
 // GetTableColumn get table columne names
 func (id RegDbID) GetTableColumn(tableName string) ([]string, error) {
 	driver, err := searchDataDriver(id)
@@ -344,6 +495,16 @@ func (id RegDbID) GetTableColumn(tableName string) ([]string, error) {
 	return driver.GetTableColumn(tableName)
 }
 
+// TableColumns get table column definitions with data type, nullable and
+// key information
+func (id RegDbID) TableColumns(tableName string) ([]*Column, error) {
+	driver, err := searchDataDriver(id)
+	if err != nil {
+		return nil, err
+	}
+	return driver.TableColumns(tableName)
+}
+
 func (result *Result) GenerateColumnByStruct(search *Query) (*ValueDefinition, error) {
 	if search.TypeInfo == nil {
 		log.Log.Errorf("internal error using TypeInfo")
@@ -383,6 +544,15 @@ func (id RegDbID) Rollback() error {
 	return driver.Rollback()
 }
 
+// DriverType database driver type of the registered database
+func (id RegDbID) DriverType() ReferenceType {
+	driver, err := searchDataDriver(id)
+	if err != nil {
+		return NoType
+	}
+	return driver.DriverType()
+}
+
 // URL URL string
 func (id RegDbID) URL() string {
 	driver, err := searchDataDriver(id)
@@ -424,6 +594,7 @@ func (id RegDbID) FreeHandler() error {
 			log.Log.Debugf("%s FreeHandler db", d.ID())
 			d.Close()
 			d.FreeHandler()
+			id.SetNamingStrategy(nil)
 			newDatabases := make([]Database, 0)
 			if i > 0 {
 				newDatabases = append(newDatabases, Databases[0:i]...)
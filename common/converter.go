@@ -0,0 +1,213 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/tknie/errorrepo"
+)
+
+// ConverterTag tag option referencing a registered converter, like
+// `flynn:"price:conv=decimal"`
+const ConverterTag = "conv"
+
+// Converter encode a Go field value into a driver value and decode the
+// database value back into the field
+type Converter interface {
+	// Encode convert the field value into a driver value
+	Encode(value any) (driver.Value, error)
+	// Decode convert database value into the field target points to,
+	// NULL values are handled before and set the field to zero value
+	Decode(src any, target any) error
+}
+
+// ConverterDataType optional interface of a converter defining the
+// data type of the column created for converted fields
+type ConverterDataType interface {
+	DataType() DataType
+}
+
+var convertersLock sync.RWMutex
+
+var converters = map[string]Converter{
+	"text":   textConverter{},
+	"valuer": valuerConverter{},
+	"gob":    gobConverter{},
+}
+
+// RegisterConverter register or replace converter used for fields
+// tagged with `conv=<name>`
+func RegisterConverter(name string, c Converter) {
+	convertersLock.Lock()
+	defer convertersLock.Unlock()
+	converters[strings.ToLower(name)] = c
+}
+
+// LookupConverter search converter registered with the given name
+func LookupConverter(name string) (Converter, bool) {
+	convertersLock.RLock()
+	defer convertersLock.RUnlock()
+	c, ok := converters[strings.ToLower(name)]
+	return c, ok
+}
+
+// FieldConverter converter referenced in the tag info of a field, nil is
+// returned if the field references no converter
+func FieldConverter(tag, fieldName string) (Converter, error) {
+	name, ok := TagOption(tag, ConverterTag)
+	if !ok {
+		return nil, nil
+	}
+	c, ok := LookupConverter(name)
+	if !ok {
+		return nil, errorrepo.NewError("DB000044", name, fieldName)
+	}
+	return c, nil
+}
+
+// converterScan scan target of converted fields keeping the database value
+type converterScan struct {
+	converter Converter
+	src       any
+}
+
+// Scan implements the Scanner interface.
+func (cs *converterScan) Scan(value any) error {
+	if b, ok := value.([]byte); ok {
+		value = bytes.Clone(b)
+	}
+	cs.src = value
+	return nil
+}
+
+// asInterface check value or pointer to value implements interface T
+func asInterface[T any](value any) (T, bool) {
+	if t, ok := value.(T); ok {
+		return t, true
+	}
+	var t T
+	v := reflect.ValueOf(value)
+	switch {
+	case !v.IsValid():
+	case v.Kind() == reflect.Pointer:
+		if !v.IsNil() {
+			t, ok := v.Elem().Interface().(T)
+			return t, ok
+		}
+	default:
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		t, ok := p.Interface().(T)
+		return t, ok
+	}
+	return t, false
+}
+
+// srcBytes database value as byte slice
+func srcBytes(src any) ([]byte, error) {
+	switch s := src.(type) {
+	case []byte:
+		return s, nil
+	case string:
+		return []byte(s), nil
+	default:
+		return nil, fmt.Errorf("cannot convert %T into bytes", src)
+	}
+}
+
+// clearTarget set value target points to to zero value
+func clearTarget(target any) {
+	v := reflect.ValueOf(target)
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	}
+}
+
+// textConverter converter of encoding.TextMarshaler fields
+type textConverter struct{}
+
+func (textConverter) Encode(value any) (driver.Value, error) {
+	m, ok := asInterface[encoding.TextMarshaler](value)
+	if !ok {
+		return nil, fmt.Errorf("%T does not implement encoding.TextMarshaler", value)
+	}
+	b, err := m.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (textConverter) Decode(src any, target any) error {
+	u, ok := target.(encoding.TextUnmarshaler)
+	if !ok {
+		return fmt.Errorf("%T does not implement encoding.TextUnmarshaler", target)
+	}
+	b, err := srcBytes(src)
+	if err != nil {
+		return err
+	}
+	return u.UnmarshalText(b)
+}
+
+// valuerConverter converter of driver.Valuer and sql.Scanner fields
+type valuerConverter struct{}
+
+func (valuerConverter) Encode(value any) (driver.Value, error) {
+	v, ok := asInterface[driver.Valuer](value)
+	if !ok {
+		return nil, fmt.Errorf("%T does not implement driver.Valuer", value)
+	}
+	return v.Value()
+}
+
+func (valuerConverter) Decode(src any, target any) error {
+	s, ok := target.(sql.Scanner)
+	if !ok {
+		return fmt.Errorf("%T does not implement sql.Scanner", target)
+	}
+	return s.Scan(src)
+}
+
+// gobConverter converter storing the field gob encoded
+type gobConverter struct{}
+
+func (gobConverter) Encode(value any) (driver.Value, error) {
+	var buffer bytes.Buffer
+	err := gob.NewEncoder(&buffer).Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (gobConverter) Decode(src any, target any) error {
+	b, err := srcBytes(src)
+	if err != nil {
+		return err
+	}
+	clearTarget(target)
+	return gob.NewDecoder(bytes.NewReader(b)).Decode(target)
+}
+
+func (gobConverter) DataType() DataType {
+	return BLOB
+}
@@ -0,0 +1,129 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type csvConverter struct{}
+
+func (csvConverter) Encode(value any) (driver.Value, error) {
+	return strings.Join(value.([]string), ","), nil
+}
+
+func (csvConverter) Decode(src any, target any) error {
+	s, err := srcBytes(src)
+	if err != nil {
+		return err
+	}
+	*(target.(*[]string)) = strings.Split(string(s), ",")
+	return nil
+}
+
+type cents int64
+
+type decimalConverter struct{}
+
+func (decimalConverter) Encode(value any) (driver.Value, error) {
+	c := value.(cents)
+	return fmt.Sprintf("%d.%02d", c/100, c%100), nil
+}
+
+func (decimalConverter) Decode(src any, target any) error {
+	var e, c int64
+	_, err := fmt.Sscanf(src.(string), "%d.%d", &e, &c)
+	*(target.(*cents)) = cents(e*100 + c)
+	return err
+}
+
+type convPoint struct {
+	X, Y int
+}
+
+type convRecord struct {
+	ID     int
+	Price  cents         `flynn:"price:conv=decimal"`
+	Tags   []string      `flynn:"tags:conv=csv"`
+	Addr   net.IP        `flynn:"addr:conv=text"`
+	Point  *convPoint    `flynn:"point:conv=gob"`
+	Amount *cents        `flynn:"amount:conv=decimal"`
+	Count  sql.NullInt64 `flynn:"count:conv=valuer"`
+}
+
+func TestConverterValues(t *testing.T) {
+	InitLog(t)
+	RegisterConverter("csv", csvConverter{})
+	RegisterConverter("decimal", decimalConverter{})
+
+	v := &convRecord{ID: 1, Price: 1234, Tags: []string{"a", "b"}, Addr: net.ParseIP("10.0.0.1"),
+		Point: &convPoint{X: 1, Y: 2}, Count: sql.NullInt64{Int64: 5, Valid: true}}
+	ti := CreateInterface(v, []string{"*"})
+	assert.Equal(t, []string{"ID", "price", "tags", "addr", "point", "amount", "count"}, ti.RowFields)
+	values, err := ti.CreateValues(v)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1, values[0])
+	assert.Equal(t, "12.34", values[1])
+	assert.Equal(t, "a,b", values[2])
+	assert.Equal(t, "10.0.0.1", values[3])
+	assert.IsType(t, []byte{}, values[4])
+	assert.Nil(t, values[5])
+	assert.Equal(t, int64(5), values[6])
+
+	vd, err := ti.CreateQueryValues()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, vd.ScanValues, 7)
+	vd.ScanValues[0].(*sql.NullInt32).Int32 = 1
+	vd.ScanValues[0].(*sql.NullInt32).Valid = true
+	for i, src := range []any{"12.34", []byte("a,b"), "10.0.0.1", values[4], nil, int64(5)} {
+		assert.NoError(t, vd.ScanValues[i+1].(sql.Scanner).Scan(src))
+	}
+	err = vd.ShiftValues()
+	if !assert.NoError(t, err) {
+		return
+	}
+	result := vd.Copy.(*convRecord)
+	assert.Equal(t, v, result)
+
+	vd, err = ti.CreateQueryValues()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, vd.ScanValues[5].(sql.Scanner).Scan("0.50"))
+	assert.NoError(t, vd.ShiftValues())
+	assert.Equal(t, cents(50), *vd.Copy.(*convRecord).Amount)
+}
+
+func TestConverterUnknown(t *testing.T) {
+	InitLog(t)
+
+	v := &struct {
+		Name string `flynn:"name:conv=unknown"`
+	}{Name: "abc"}
+	ti := CreateInterface(v, []string{"*"})
+	_, err := ti.CreateValues(v)
+	assert.Error(t, err)
+	c, err := FieldConverter("name:key", "Name")
+	assert.NoError(t, err)
+	assert.Nil(t, c)
+}
@@ -17,8 +17,6 @@ import (
 	"fmt"
 	"strings"
 	"time"
-
-	"github.com/tknie/log"
 )
 
 type DataType byte
@@ -37,11 +35,13 @@ const (
 	Date
 	BLOB
 	Character
+	Float
+	Boolean
 )
 
 var sqlTypes = []string{"", "VARCHAR(%d)", "TEXT", "UNICODE(%d)", "INTEGER",
 	"DECIMAL(%d,%d)", "INTEGER", "BIT(%d)", "BINARY(%d)",
-	"TIMESTAMP(%s)", "DATE", "BLOB(%d)", "CHAR(%d)"}
+	"TIMESTAMP(%s)", "DATE", "BLOB(%d)", "CHAR(%d)", "FLOAT", "BOOL"}
 
 func (dt DataType) SqlType(arg ...any) string {
 	if dt == Bytes {
@@ -105,78 +105,13 @@ func CreateHeader(ct []*sql.ColumnType) []*Column {
 	return header
 }
 
+// CreateTypeData create scan targets of all columns using the registered
+// column types, unknown types are scanned as raw value
 func CreateTypeData(ct []*sql.ColumnType) []interface{} {
-	scanData := make([]interface{}, 0)
+	scanData := make([]interface{}, 0, len(ct))
 	for _, t := range ct {
-		switch t.DatabaseTypeName() {
-		case "VARCHAR", "TEXT", "UNICODE":
-			//if nok, _ := t.Nullable(); nok {
-			v := sql.NullString{}
-			scanData = append(scanData, &v)
-			//} else {
-			//	s := ""
-			//	scanData = append(scanData, &s)
-			//}
-		case "NUMBER", "INT4", "INTEGER":
-			if nok, _ := t.Nullable(); nok {
-				scanData = append(scanData, &sql.NullInt32{})
-			} else {
-				i := int32(0)
-				scanData = append(scanData, &i)
-			}
-		case "BIGINT", "INT8":
-			if nok, _ := t.Nullable(); nok {
-				scanData = append(scanData, &sql.NullInt64{})
-			} else {
-				i := int64(0)
-				scanData = append(scanData, &i)
-			}
-		case "DECIMAL":
-			if nok, _ := t.Nullable(); nok {
-				scanData = append(scanData, &sql.NullFloat64{})
-			} else {
-				f := float64(0)
-				scanData = append(scanData, &f)
-			}
-		case "BIT":
-			if nok, _ := t.Nullable(); nok {
-				scanData = append(scanData, &sql.NullByte{})
-			} else {
-				b := byte(0)
-				scanData = append(scanData, &b)
-			}
-		case "BPCHAR":
-			if nok, _ := t.Nullable(); nok {
-				scanData = append(scanData, &sql.NullString{})
-			} else {
-				b := ""
-				scanData = append(scanData, &b)
-			}
-		case "BLOB", "BINARY", "BYTEA", "DATA":
-			if nok, _ := t.Nullable(); nok {
-				scanData = append(scanData, &NullBytes{})
-			} else {
-				b := make([]byte, 0)
-				scanData = append(scanData, &b)
-			}
-		case "TIMESTAMP":
-			if nok, _ := t.Nullable(); nok {
-				scanData = append(scanData, &sql.NullTime{})
-			} else {
-				var t time.Time
-				scanData = append(scanData, &t)
-			}
-		case "UNSIGNED INT":
-			if nok, _ := t.Nullable(); nok {
-				scanData = append(scanData, &NullUint{})
-			} else {
-				v := uint64(0)
-				scanData = append(scanData, &v)
-			}
-		default:
-			fmt.Println("Type not defined ", t.Name(), t.DatabaseTypeName())
-			log.Log.Fatal("Type not defined " + t.Name())
-		}
+		nullable, _ := t.Nullable()
+		scanData = append(scanData, columnTypeOf(t).ScanTarget(nullable))
 	}
 	return scanData
 }
@@ -30,6 +30,20 @@ func InitLog(t *testing.T) {
 	log.Log.Debugf("TEST: %s", t.Name())
 }
 
+// registerTestDatabase register fake database driver, it is removed when
+// the test finishes
+func registerTestDatabase(t *testing.T, db Database) {
+	RegisterDbClient(db)
+	t.Cleanup(func() {
+		for i, d := range Databases {
+			if d == db {
+				Databases = append(Databases[:i], Databases[i+1:]...)
+				break
+			}
+		}
+	})
+}
+
 func startLog() {
 	fmt.Println("Init logging")
 	fileName := "common.trace.log"
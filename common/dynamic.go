@@ -14,8 +14,10 @@ package common
 import (
 	"bytes"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
 	"reflect"
 	"slices"
 	"strconv"
@@ -52,6 +54,13 @@ type typeInterface struct {
 	ValueRefTo []any
 	ScanValues []any
 	TagInfo    []TagInfo
+	// NativeArrays slice fields are database arrays, otherwise slices are
+	// stored JSON encoded
+	NativeArrays bool
+	// Naming naming strategy of field names without tag name
+	Naming NamingStrategy
+	// Excluded field names excluded from the field set
+	Excluded map[string]void
 }
 
 type SubInterface interface {
@@ -59,7 +68,15 @@ type SubInterface interface {
 	ParseData(sub []byte) error
 }
 
+// CreateInterface create dynamic interface of the structure using the
+// Go field names as column names
 func CreateInterface(i interface{}, createFields []string) *typeInterface {
+	return CreateInterfaceNaming(i, createFields, nil)
+}
+
+// CreateInterfaceNaming create dynamic interface of the structure mapping
+// the Go field names with the given naming strategy
+func CreateInterfaceNaming(i interface{}, createFields []string, naming NamingStrategy) *typeInterface {
 	fields := createFields
 	if fields == nil {
 		fields = []string{"*"}
@@ -71,7 +88,7 @@ func CreateInterface(i interface{}, createFields []string) *typeInterface {
 	log.Log.Debugf("Create dynamic interface with fields %#v", fields)
 	set := make(map[string]void) // New empty set
 	dynamic := &typeInterface{DataType: i, RowNames: make(map[string][]string),
-		RowFields: make([]string, 0), FieldSet: set}
+		RowFields: make([]string, 0), FieldSet: set, Naming: naming}
 	for _, f := range fields {
 		switch f {
 		case "*":
@@ -82,10 +99,19 @@ func CreateInterface(i interface{}, createFields []string) *typeInterface {
 		default:
 			dynamic.SetType = GivenSet
 			dynamic.FieldSet[strings.ToLower(f)] = member
+			dynamic.FieldSet[strings.ToLower(naming.ColumnName(f))] = member
 		}
 	}
 	log.Log.Debugf("FieldSet defined: %#v", dynamic.FieldSet)
-	dynamic.generateFieldNames(ri)
+	fn, key, cacheable := cachedFieldNames(ri, fields, naming)
+	if fn != nil {
+		dynamic.RowFields, dynamic.RowNames = fn.copyFieldNames()
+		return dynamic
+	}
+	dynamic.generateFieldNames(ri, "")
+	if cacheable {
+		storeFieldNames(key, dynamic.RowFields, dynamic.RowNames)
+	}
 	log.Log.Debugf("Final created field list generated %#v", dynamic.RowFields)
 	return dynamic
 }
@@ -127,7 +153,8 @@ func (dynamic *typeInterface) CreateQueryValues() (*ValueDefinition, error) {
 		log.Log.Debugf("Pointer type: %T", elemValue.Interface())
 	}
 	log.Log.Debugf("Final type: %T", elemValue.Interface())
-	err := dynamic.generateField(elemValue, true)
+	dynamic.resetValues()
+	err := dynamic.generateField(elemValue, true, "")
 	if err != nil {
 		return nil, err
 	}
@@ -139,7 +166,7 @@ func (dynamic *typeInterface) CreateQueryValues() (*ValueDefinition, error) {
 // CreateValues create query value copy of struct
 // deprecated: should not be used anymore
 func (dynamic *typeInterface) CreateValues(value interface{}) ([]any, error) {
-	dynamic.ValueRefTo = make([]any, 0)
+	dynamic.resetValues()
 	if dynamic.SetType == EmptySet {
 		log.Log.Debugf("Empty set defined")
 		return nil, nil
@@ -149,7 +176,17 @@ func (dynamic *typeInterface) CreateValues(value interface{}) ([]any, error) {
 	if valueOf.Type().Kind() == reflect.Pointer {
 		valueOf = valueOf.Elem()
 	}
-	err := dynamic.generateField(valueOf, false)
+	if plan := dynamic.cachedValuePlan(valueOf.Type()); plan != nil {
+		values, err := plan.values(valueOf)
+		if err != nil {
+			return nil, err
+		}
+		dynamic.ValueRefTo = values
+		// all planned fields are normal fields
+		dynamic.TagInfo = make([]TagInfo, len(values))
+		return values, nil
+	}
+	err := dynamic.generateField(valueOf, false, "")
 	if err != nil {
 		return nil, err
 	}
@@ -157,30 +194,51 @@ func (dynamic *typeInterface) CreateValues(value interface{}) ([]any, error) {
 	return dynamic.ValueRefTo, nil
 }
 
+// resetValues reset values generated by previous value creation
+func (dynamic *typeInterface) resetValues() {
+	dynamic.ValueRefTo = make([]any, 0)
+	dynamic.ScanValues = nil
+	dynamic.TagInfo = nil
+}
+
 // generateField generate field values for dynamic query.
 // 'scan' is used to consider case for read (field creation out of database) or
-// write (no creation, data is used by application)
-func (dynamic *typeInterface) generateField(elemValue reflect.Value, readScan bool) error {
-	log.Log.Debugf("Generate field of Struct: %T %s -> scan=%v",
-		elemValue.Interface(), elemValue.Type().Name(), readScan)
+// write (no creation, data is used by application). Field names of flattened
+// structures get the given prefix.
+func (dynamic *typeInterface) generateField(elemValue reflect.Value, readScan bool, prefix string) error {
+	log.Log.Debugf("Generate field of Struct: %s %s -> scan=%v",
+		elemValue.Type().String(), elemValue.Type().Name(), readScan)
 	defer log.Log.Debugf("generated field of struct %s", elemValue.Type().Name())
-	for fi := 0; fi < elemValue.NumField(); fi++ {
-		fieldType := elemValue.Type().Field(fi)
-		tag := fieldType.Tag
+	for fi, fm := range structMetadata(elemValue.Type()) {
+		fieldType := fm.field
 		cv := elemValue.Field(fi)
-		d := tag.Get(TagName)
-		tagName, tagInfo := TagInfoParse(d)
-		fieldName := fieldType.Name
-		if tagName != "" {
-			fieldName = tagName
-		}
+		tagName, tagInfo := fm.tagName, fm.tagInfo
+		fieldName := fm.columnName(dynamic.Naming, prefix)
 		log.Log.Debugf("%s: kind %v tags = %s", fieldName, cv.Kind(), tagName)
+		if fm.lazy {
+			continue
+		}
+		if tagInfo != IgnoreTag {
+			converter, err := fm.fieldConverter(fieldName)
+			if err != nil {
+				return err
+			}
+			if converter != nil {
+				if dynamic.checkFieldSet(fieldName) {
+					err = dynamic.generateConverterField(cv, converter, readScan)
+					if err != nil {
+						return err
+					}
+				}
+				continue
+			}
+		}
 		switch tagInfo {
 		case IgnoreTag:
 			continue
 		case SubTag:
 			log.Log.Debugf("is nil = %v scan = %v", cv.IsNil(), readScan)
-			checkField := dynamic.checkFieldSet(fieldType.Name)
+			checkField := dynamic.checkFieldSet(fieldName)
 			if checkField {
 				di := cv.Interface()
 				log.Log.Debugf("Sub interface = %v/%T", di, di)
@@ -206,9 +264,9 @@ func (dynamic *typeInterface) generateField(elemValue reflect.Value, readScan bo
 				continue
 			}
 		case YAMLTag, XMLTag, JSONTag:
-			checkField := dynamic.checkFieldSet(fieldType.Name)
+			checkField := dynamic.checkFieldSet(fieldName)
 			if checkField {
-				if cv.Kind() == reflect.Pointer {
+				if cv.Kind() == reflect.Pointer || cv.Kind() == reflect.Struct {
 					if !readScan {
 						switch tagInfo {
 						case YAMLTag:
@@ -231,9 +289,14 @@ func (dynamic *typeInterface) generateField(elemValue reflect.Value, readScan bo
 							dynamic.ValueRefTo = append(dynamic.ValueRefTo, string(out))
 						}
 					} else {
-						x := reflect.Indirect(reflect.New(cv.Type().Elem()))
-						cv.Set(x.Addr())
-						di := cv.Interface()
+						var di any
+						if cv.Kind() == reflect.Struct {
+							di = cv.Addr().Interface()
+						} else {
+							x := reflect.Indirect(reflect.New(cv.Type().Elem()))
+							cv.Set(x.Addr())
+							di = cv.Interface()
+						}
 						log.Log.Debugf("Add YAML,XML,JSON into value %T %p", di, di)
 						dynamic.ValueRefTo = append(dynamic.ValueRefTo, di)
 					}
@@ -250,6 +313,24 @@ func (dynamic *typeInterface) generateField(elemValue reflect.Value, readScan bo
 			}
 			continue
 		case NormalTag, KeyTag, IndexTag:
+			if IsArrayType(cv.Type()) {
+				if dynamic.checkFieldSet(fieldName) {
+					err := dynamic.generateArrayField(cv, readScan)
+					if err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if isNullField(cv.Type()) {
+				if dynamic.checkFieldSet(fieldName) {
+					err := dynamic.generateNullableField(cv, readScan)
+					if err != nil {
+						return err
+					}
+				}
+				continue
+			}
 			if cv.Kind() == reflect.Pointer {
 				// x := reflect.New(cv.Type().Elem())
 				/*			x := reflect.Indirect(reflect.New(cv.Type().Elem()))
@@ -271,9 +352,9 @@ func (dynamic *typeInterface) generateField(elemValue reflect.Value, readScan bo
 			}
 			if cv.Kind() == reflect.Struct {
 				log.Log.Debugf("Work on struct %s", fieldType.Name)
-				switch cv.Interface().(type) {
-				case time.Time:
-					checkField := dynamic.checkFieldSet(fieldType.Name)
+				switch cv.Type() {
+				case timeType:
+					checkField := dynamic.checkFieldSet(fieldName)
 					if checkField {
 						ptr := cv.Addr()
 						t := reflect.TypeOf(cv)
@@ -331,9 +412,10 @@ func (dynamic *typeInterface) generateField(elemValue reflect.Value, readScan bo
 						dynamic.TagInfo = append(dynamic.TagInfo, JSONTag)
 						continue
 					default:
-						dynamic.generateField(cv, readScan)
-						//							dynamic.ValueRefTo = append(dynamic.ValueRefTo, "")
-						//							dynamic.TagInfo = append(dynamic.TagInfo, NormalTag)
+						err := dynamic.generateField(cv, readScan, prefix+StructPrefix(fm.field, dynamic.Naming))
+						if err != nil {
+							return err
+						}
 						continue
 					}
 				}
@@ -354,27 +436,12 @@ func (dynamic *typeInterface) generateField(elemValue reflect.Value, readScan bo
 						ptrInt := ptr.Interface()
 						log.Log.Debugf("Add value %T pointer=%p %s %s", ptrInt, ptrInt, fieldName, elemValue.Type().Name())
 						dynamic.ValueRefTo = append(dynamic.ValueRefTo, ptrInt)
-						switch cv.Kind() {
-						case reflect.String:
-							dynamic.ScanValues = append(dynamic.ScanValues, &sql.NullString{})
-						case reflect.Bool:
-							dynamic.ScanValues = append(dynamic.ScanValues, &sql.NullBool{})
-						case reflect.Int8:
-							dynamic.ScanValues = append(dynamic.ScanValues, &sql.NullByte{})
-						case reflect.Int16:
-							dynamic.ScanValues = append(dynamic.ScanValues, &sql.NullInt16{})
-						case reflect.Int32, reflect.Int:
-							dynamic.ScanValues = append(dynamic.ScanValues, &sql.NullInt32{})
-						case reflect.Int64:
-							dynamic.ScanValues = append(dynamic.ScanValues, &sql.NullInt64{})
-						case reflect.Uint64:
-							dynamic.ScanValues = append(dynamic.ScanValues, &sql.NullString{})
-						case reflect.Float32, reflect.Float64:
-							dynamic.ScanValues = append(dynamic.ScanValues, &sql.NullFloat64{})
-						default:
+						scanValue := nullScanValue(cv.Type())
+						if scanValue == nil {
 							log.Log.Debugf("'%s' dynamic Kind not defined for SQL %s", fieldType.Name, cv.Kind().String())
-							dynamic.ScanValues = append(dynamic.ScanValues, ptrInt)
+							scanValue = ptrInt
 						}
+						dynamic.ScanValues = append(dynamic.ScanValues, scanValue)
 						dynamic.TagInfo = append(dynamic.TagInfo, NormalTag)
 					} else {
 						switch cv.Kind() {
@@ -407,36 +474,153 @@ func (dynamic *typeInterface) generateField(elemValue reflect.Value, readScan bo
 	return nil
 }
 
+// generateArrayField generate field value of slice fields stored as
+// database array or JSON encoded if no native arrays are available
+func (dynamic *typeInterface) generateArrayField(cv reflect.Value, readScan bool) error {
+	log.Log.Debugf("Array field %s -> scan=%v native=%v", cv.Type(), readScan, dynamic.NativeArrays)
+	switch {
+	case readScan:
+		ptr := cv.Addr().Interface()
+		dynamic.ValueRefTo = append(dynamic.ValueRefTo, ptr)
+		if dynamic.NativeArrays {
+			dynamic.ScanValues = append(dynamic.ScanValues, ptr)
+		} else {
+			dynamic.ScanValues = append(dynamic.ScanValues, &sql.NullString{})
+		}
+	case cv.IsNil():
+		dynamic.ValueRefTo = append(dynamic.ValueRefTo, nil)
+	case dynamic.NativeArrays:
+		dynamic.ValueRefTo = append(dynamic.ValueRefTo, cv.Interface())
+	default:
+		out, err := json.Marshal(cv.Interface())
+		if err != nil {
+			return err
+		}
+		dynamic.ValueRefTo = append(dynamic.ValueRefTo, string(out))
+	}
+	if dynamic.NativeArrays {
+		dynamic.TagInfo = append(dynamic.TagInfo, NormalTag)
+	} else {
+		dynamic.TagInfo = append(dynamic.TagInfo, JSONTag)
+	}
+	return nil
+}
+
+// generateNullableField generate field value of pointer fields and fields
+// implementing sql.Scanner like sql.NullString. NULL values are read as
+// nil pointer or invalid null type, nil pointers are written as NULL.
+func (dynamic *typeInterface) generateNullableField(cv reflect.Value, readScan bool) error {
+	log.Log.Debugf("Nullable field %s -> scan=%v", cv.Type(), readScan)
+	if readScan {
+		ptr := cv.Addr().Interface()
+		dynamic.ValueRefTo = append(dynamic.ValueRefTo, ptr)
+		if cv.Kind() == reflect.Pointer {
+			dynamic.ScanValues = append(dynamic.ScanValues, nullScanValue(cv.Type().Elem()))
+		} else {
+			// null types are scanned directly
+			dynamic.ScanValues = append(dynamic.ScanValues, ptr)
+		}
+	} else {
+		switch {
+		case cv.Kind() == reflect.Pointer && cv.IsNil():
+			dynamic.ValueRefTo = append(dynamic.ValueRefTo, nil)
+		case cv.Kind() == reflect.Pointer:
+			dynamic.ValueRefTo = append(dynamic.ValueRefTo, cv.Elem().Interface())
+		default:
+			var value any = cv.Interface()
+			if valuer, ok := value.(driver.Valuer); ok {
+				v, err := valuer.Value()
+				if err != nil {
+					return err
+				}
+				value = v
+			}
+			dynamic.ValueRefTo = append(dynamic.ValueRefTo, value)
+		}
+	}
+	dynamic.TagInfo = append(dynamic.TagInfo, NormalTag)
+	return nil
+}
+
+// generateConverterField generate field value of fields using a converter,
+// the converter scan value keeps the database value until shifted
+func (dynamic *typeInterface) generateConverterField(cv reflect.Value, converter Converter, readScan bool) error {
+	log.Log.Debugf("Converter field %s -> scan=%v", cv.Type(), readScan)
+	if readScan {
+		dynamic.ValueRefTo = append(dynamic.ValueRefTo, cv.Addr().Interface())
+	} else {
+		fv := cv
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				fv = reflect.Value{}
+			} else {
+				fv = fv.Elem()
+			}
+		}
+		if fv.IsValid() {
+			value, err := converter.Encode(fv.Interface())
+			if err != nil {
+				return err
+			}
+			dynamic.ValueRefTo = append(dynamic.ValueRefTo, value)
+		} else {
+			dynamic.ValueRefTo = append(dynamic.ValueRefTo, nil)
+		}
+	}
+	dynamic.ScanValues = append(dynamic.ScanValues, &converterScan{converter: converter})
+	dynamic.TagInfo = append(dynamic.TagInfo, ConvTag)
+	return nil
+}
+
 func (dynamic *typeInterface) checkFieldSet(fieldName string) bool {
 	ok := true
 	log.Log.Debugf("Check %s in %#v", strings.ToLower(fieldName), dynamic.FieldSet)
 	if dynamic.SetType == GivenSet {
 		_, ok = dynamic.FieldSet[strings.ToLower(fieldName)]
 	}
+	if _, excluded := dynamic.Excluded[strings.ToLower(fieldName)]; excluded {
+		ok = false
+	}
 	log.Log.Debugf("Restrict to %v", ok)
 
 	return ok
 }
 
 // generateFieldNames examine all structure-tags in the given structure and build up
-// field names map pointing to corresponding path with names of structures
-func (dynamic *typeInterface) generateFieldNames(ri reflect.Type) {
+// field names map pointing to corresponding path with names of structures.
+// Field names of flattened structures get the given prefix.
+func (dynamic *typeInterface) generateFieldNames(ri reflect.Type, prefix string) {
 	if log.IsDebugLevel() {
 		log.Log.Debugf("Generate field names...")
 	}
 	if ri.Kind() != reflect.Struct {
 		return
 	}
-	for fi := 0; fi < ri.NumField(); fi++ {
-		ct := ri.Field(fi)
-		fieldName := ct.Name
+	for _, fm := range structMetadata(ri) {
+		ct := fm.field
+		tagInfo := fm.tagInfo
+		fieldName := fm.columnName(dynamic.Naming, prefix)
 		log.Log.Debugf("Work on fieldname %s", fieldName)
-		tag := ct.Tag.Get(TagName)
-		tagName, tagInfo := TagInfoParse(tag)
-		if tagName != "" {
-			fieldName = tagName
-		}
 		log.Log.Debugf("Field tag option %s", tagInfo)
+		if fm.lazy {
+			if tagInfo != IgnoreTag && dynamic.checkFieldSet(fieldName) {
+				dynamic.RowNames["#lazylob"] = append(dynamic.RowNames["#lazylob"], fieldName)
+			}
+			continue
+		}
+		if fm.auto != "" && tagInfo != IgnoreTag &&
+			(fm.auto == SoftDeleteOption || dynamic.checkFieldSet(fieldName)) {
+			dynamic.RowNames["#"+fm.auto] = append(dynamic.RowNames["#"+fm.auto], fieldName)
+		}
+		if fm.hasConverter && tagInfo != IgnoreTag {
+			if tagInfo == KeyTag {
+				dynamic.RowNames["#key"] = []string{fieldName}
+			}
+			if dynamic.checkFieldSet(fieldName) {
+				dynamic.RowFields = append(dynamic.RowFields, fieldName)
+			}
+			continue
+		}
 		switch tagInfo {
 		case KeyTag:
 			dynamic.RowNames["#key"] = []string{fieldName}
@@ -472,8 +656,12 @@ func (dynamic *typeInterface) generateFieldNames(ri reflect.Type) {
 		if st.Kind() == reflect.Struct {
 			log.Log.Debugf("Struct-Kind of %s", st.Name())
 			//continue generate field names
-			if st.Name() != "Time" {
-				dynamic.generateFieldNames(st)
+			if isNullType(st) {
+				if dynamic.checkFieldSet(fieldName) {
+					dynamic.RowFields = append(dynamic.RowFields, fieldName)
+				}
+			} else if st.Name() != "Time" {
+				dynamic.generateFieldNames(st, prefix+StructPrefix(fm.field, dynamic.Naming))
 			} else {
 				ok := dynamic.checkFieldSet(fieldName)
 				if ok {
@@ -492,6 +680,9 @@ func (dynamic *typeInterface) generateFieldNames(ri reflect.Type) {
 			}
 		}
 		// Handle special case for pointer and slices
+		if IsArrayType(ct.Type) {
+			continue
+		}
 		switch ct.Type.Kind() {
 		case reflect.Ptr:
 			// dynamic.generateFieldNames(ct.Type.Elem())
@@ -500,7 +691,7 @@ func (dynamic *typeInterface) generateFieldNames(ri reflect.Type) {
 			if sliceT.Kind() == reflect.Ptr {
 				sliceT = sliceT.Elem()
 			}
-			dynamic.generateFieldNames(sliceT)
+			dynamic.generateFieldNames(sliceT, prefix)
 		}
 	}
 	log.Log.Debugf("Field list generated %#v", dynamic.RowFields)
@@ -542,6 +733,12 @@ func (vd *ValueDefinition) ShiftValues() error {
 			if err != nil {
 				return err
 			}
+		case ConvTag:
+			err := vd.ShiftConverterValues(d, v)
+			if err != nil {
+				log.Log.Debugf("Error in shift converter values: %v", err)
+				return err
+			}
 
 		case IgnoreTag: // is ignored
 		}
@@ -550,48 +747,36 @@ func (vd *ValueDefinition) ShiftValues() error {
 }
 
 func (vd *ValueDefinition) ShiftNormalValues(d int, v any) error {
+	if v == vd.Values[d] {
+		log.Log.Debugf("(%d) Value scanned directly %T", d, v)
+		return nil
+	}
 	if _, ok := v.(sqlInterface); ok {
 		vv, err := v.(sqlInterface).Value()
 		if err != nil {
 			log.Log.Errorf("SQL interface error: %v", err)
 			return err
 		}
+		target := reflect.ValueOf(vd.Values[d]).Elem()
 		if vv != nil {
 			log.Log.Debugf("(%d) Found value %T pointer=%p", d, vd.Values[d], vd.Values[d])
 			log.Log.Debugf("Shift values %v", vv)
-			switch vt := vd.Values[d].(type) {
-			case *int:
-				switch vvv := vv.(type) {
-				case int:
-					*vt = int(vvv)
-				case int32:
-					*vt = int(vvv)
-				case int64:
-					*vt = int(vvv)
-				default:
-					log.Log.Debugf("Unknown type %T", vv)
+			if target.Kind() == reflect.Pointer {
+				if target.IsNil() {
+					target.Set(reflect.New(target.Type().Elem()))
 				}
-			case *float32:
-				*vt = vv.(float32)
-			case *float64:
-				*vt = vv.(float64)
-			case *int64:
-				*vt = vv.(int64)
-			case *uint64:
-				v := vv.(string)
-				*vt, err = strconv.ParseUint(v, 0, 64)
-				if err != nil {
-					return err
-				}
-			case *string:
-				*vt = vv.(string)
-			case *time.Time:
-				*vt = vv.(time.Time)
-			default:
-				log.Log.Fatalf("Unknown type for shifting %s at index %d value %T <- %T", vd.dynamic.RowFields[d], d, vd.Values[d], vv)
+				err = shiftValue(target.Interface(), vv)
+			} else {
+				err = shiftValue(vd.Values[d], vv)
+			}
+			if err != nil {
+				log.Log.Debugf("Unknown type for shifting %s at index %d value %T <- %T",
+					vd.dynamic.RowFields[d], d, vd.Values[d], vv)
+				return err
 			}
 		} else {
 			log.Log.Debugf("SQL interface value nil")
+			target.Set(reflect.Zero(target.Type()))
 		}
 	} else {
 		log.Log.Debugf("Error sql interface: %T", v)
@@ -599,6 +784,51 @@ func (vd *ValueDefinition) ShiftNormalValues(d int, v any) error {
 	return nil
 }
 
+// shiftValue set driver value into the value the target points to
+func shiftValue(target any, vv driver.Value) error {
+	rv := reflect.ValueOf(target).Elem()
+	val := reflect.ValueOf(vv)
+	switch {
+	case rv.Kind() == val.Kind() && val.Type().ConvertibleTo(rv.Type()):
+	case isNumberKind(rv.Kind()) && isNumberKind(val.Kind()):
+	case rv.Kind() == reflect.String && val.Type() == reflect.TypeOf([]byte{}):
+	case rv.Kind() >= reflect.Uint && rv.Kind() <= reflect.Uint64 && val.Kind() == reflect.String:
+		u, err := strconv.ParseUint(vv.(string), 0, 64)
+		if err != nil {
+			return err
+		}
+		rv.SetUint(u)
+		return nil
+	default:
+		return errorrepo.NewError("DB000045", fmt.Sprintf("%T", vv), rv.Type().String())
+	}
+	rv.Set(val.Convert(rv.Type()))
+	return nil
+}
+
+// isNumberKind check kind is an integer or float kind
+func isNumberKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Float64
+}
+
+// ShiftConverterValues decode database value into the field using the
+// field converter, fields are set to zero value for NULL values
+func (vd *ValueDefinition) ShiftConverterValues(d int, v any) error {
+	cs := v.(*converterScan)
+	if cs.src == nil {
+		clear(vd.Values[d])
+		return nil
+	}
+	target := reflect.ValueOf(vd.Values[d])
+	if target.Elem().Kind() == reflect.Pointer {
+		if target.Elem().IsNil() {
+			target.Elem().Set(reflect.New(target.Elem().Type().Elem()))
+		}
+		return cs.converter.Decode(cs.src, target.Elem().Interface())
+	}
+	return cs.converter.Decode(cs.src, vd.Values[d])
+}
+
 func (vd *ValueDefinition) ShiftTransformContentValues(d int, v any) error {
 	log.Log.Debugf("(%d) Found value %T of %#v", d, vd.Values[d], vd.Values[d])
 	fieldValue := v.(*sql.NullString)
@@ -650,6 +880,89 @@ func (vd *ValueDefinition) ShiftTransformContentValues(d int, v any) error {
 	return nil
 }
 
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// IsNullableType check if Go type can represent NULL database values like
+// pointers, slices or null types implementing sql.Scanner
+func IsNullableType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	default:
+	}
+	return isNullType(t) || t == lazyLOBType
+}
+
+// IsArrayType check if the slice type is stored as database array, byte
+// slices are excluded
+func IsArrayType(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+	e := t.Elem()
+	switch e.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Float32, reflect.Float64:
+		return true
+	default:
+	}
+	return e == timeType
+}
+
+// isNullType check type is a null type structure like sql.NullString
+func isNullType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && reflect.PointerTo(t).Implements(scannerType)
+}
+
+// isValueType check type is a basic value type or time
+func isValueType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64:
+		return true
+	default:
+	}
+	return (t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64) || t == timeType
+}
+
+// isNullField check field is handled as nullable field, pointers to
+// value types and null types
+func isNullField(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		return isValueType(t.Elem())
+	}
+	return isNullType(t)
+}
+
+// nullScanValue scan value used to read values of the given type,
+// nil is returned if no null type is available
+func nullScanValue(t reflect.Type) any {
+	if t == timeType {
+		return &sql.NullTime{}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &sql.NullString{}
+	case reflect.Bool:
+		return &sql.NullBool{}
+	case reflect.Int8:
+		return &sql.NullByte{}
+	case reflect.Int16:
+		return &sql.NullInt16{}
+	case reflect.Int32, reflect.Int:
+		return &sql.NullInt32{}
+	case reflect.Int64:
+		return &sql.NullInt64{}
+	case reflect.Uint64:
+		return &sql.NullString{}
+	case reflect.Float32, reflect.Float64:
+		return &sql.NullFloat64{}
+	default:
+	}
+	return nil
+}
+
 func clear(v interface{}) {
 	p := reflect.ValueOf(v).Elem()
 	p.Set(reflect.Zero(p.Type()))
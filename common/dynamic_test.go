@@ -17,6 +17,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/tknie/log"
@@ -135,3 +136,136 @@ func TestDynamicQueryAll(t *testing.T) {
 		uint64(0), int64(0), []uint8{}}, createValue)
 
 }
+
+type nullRecord struct {
+	ID      int
+	Name    string
+	Note    *string
+	Counter *int64
+	Created *time.Time
+	Title   sql.NullString
+	Amount  sql.NullInt64
+}
+
+func TestDynamicNullable(t *testing.T) {
+	InitLog(t)
+
+	note := "note"
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	v := &nullRecord{ID: 1, Name: "abc", Note: &note, Created: &now,
+		Title: sql.NullString{String: "title", Valid: true}}
+	ti := CreateInterface(v, []string{"*"})
+	assert.Equal(t, []string{"ID", "Name", "Note", "Counter", "Created", "Title", "Amount"}, ti.RowFields)
+	values, err := ti.CreateValues(v)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []any{1, "abc", "note", nil, now, "title", nil}, values)
+
+	vd, err := ti.CreateQueryValues()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, vd.ScanValues, 7)
+	for i, src := range []any{int64(2), nil, "xyz", int64(42), nil, nil, int64(12)} {
+		assert.NoError(t, vd.ScanValues[i].(sql.Scanner).Scan(src))
+	}
+	assert.NoError(t, vd.ShiftValues())
+	xyz := "xyz"
+	counter := int64(42)
+	assert.Equal(t, &nullRecord{ID: 2, Note: &xyz, Counter: &counter,
+		Amount: sql.NullInt64{Int64: 12, Valid: true}}, vd.Copy)
+
+	for i, src := range []any{int64(3), "n", nil, nil, now, "t", nil} {
+		assert.NoError(t, vd.ScanValues[i].(sql.Scanner).Scan(src))
+	}
+	assert.NoError(t, vd.ShiftValues())
+	assert.Equal(t, &nullRecord{ID: 3, Name: "n", Created: &now,
+		Title: sql.NullString{String: "t", Valid: true}}, vd.Copy)
+}
+
+type arrayRecord struct {
+	ID      int
+	Tags    []string
+	Numbers []int64
+	Dates   []time.Time
+}
+
+func TestDynamicArray(t *testing.T) {
+	InitLog(t)
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	v := &arrayRecord{ID: 1, Tags: []string{"a", "b"}, Dates: []time.Time{now}}
+	ti := CreateInterface(v, []string{"*"})
+	assert.Equal(t, []string{"ID", "Tags", "Numbers", "Dates"}, ti.RowFields)
+	values, err := ti.CreateValues(v)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []any{1, `["a","b"]`, nil, `["2024-01-02T03:04:05Z"]`}, values)
+
+	vd, err := ti.CreateQueryValues()
+	if !assert.NoError(t, err) {
+		return
+	}
+	for i, src := range []any{int64(1), []byte(`["x"]`), "[1,2]", nil} {
+		assert.NoError(t, vd.ScanValues[i].(sql.Scanner).Scan(src))
+	}
+	assert.NoError(t, vd.ShiftValues())
+	assert.Equal(t, &arrayRecord{ID: 1, Tags: []string{"x"}, Numbers: []int64{1, 2}}, vd.Copy)
+
+	ti.NativeArrays = true
+	values, err = ti.CreateValues(v)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{1, []string{"a", "b"}, nil, []time.Time{now}}, values)
+	vd, err = ti.CreateQueryValues()
+	assert.NoError(t, err)
+	assert.Equal(t, vd.Values[1], vd.ScanValues[1])
+}
+
+type prefixAddress struct {
+	Street string `flynn:"street"`
+	City   string `flynn:"city"`
+}
+
+type prefixBase struct {
+	ID int
+}
+
+type prefixRecord struct {
+	prefixBase
+	Home  prefixAddress  `flynn:"home:prefix=home_"`
+	Work  *prefixAddress `flynn:"work:prefix=work_"`
+	Other prefixAddress  `flynn:"other:json"`
+}
+
+func TestDynamicPrefix(t *testing.T) {
+	InitLog(t)
+
+	v := &prefixRecord{prefixBase: prefixBase{ID: 1}, Home: prefixAddress{Street: "Main", City: "A"},
+		Work: &prefixAddress{Street: "Side", City: "B"}, Other: prefixAddress{City: "C"}}
+	ti := CreateInterface(v, []string{"*"})
+	assert.Equal(t, []string{"ID", "home_street", "home_city", "work_street", "work_city", "other"}, ti.RowFields)
+	values, err := ti.CreateValues(v)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []any{1, "Main", "A", "Side", "B", `{"Street":"","City":"C"}`}, values)
+
+	ti = CreateInterface(v, []string{"home_city", "work_street", "other"})
+	vd, err := ti.CreateQueryValues()
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, vd.ScanValues, 3) {
+		return
+	}
+	for i, src := range []any{"X", "Y", `{"City":"Z"}`} {
+		assert.NoError(t, vd.ScanValues[i].(sql.Scanner).Scan(src))
+	}
+	assert.NoError(t, vd.ShiftValues())
+	result := vd.Copy.(*prefixRecord)
+	assert.Equal(t, "X", result.Home.City)
+	assert.Equal(t, "Y", result.Work.Street)
+	assert.Equal(t, "Z", result.Other.City)
+}
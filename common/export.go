@@ -0,0 +1,420 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/tknie/errorrepo"
+	"github.com/tknie/log"
+)
+
+// ExportFormat output format of exported records
+type ExportFormat byte
+
+const (
+	// CSVExport comma separated values with header line
+	CSVExport ExportFormat = iota
+	// JSONLinesExport one JSON object per line
+	JSONLinesExport
+	// JSONExport indented JSON array of objects
+	JSONExport
+	// XMLExport XML document with one element per record
+	XMLExport
+)
+
+var exportFormatNames = []string{"csv", "jsonl", "json", "xml"}
+
+// String name of the export format
+func (format ExportFormat) String() string {
+	if int(format) < len(exportFormatNames) {
+		return exportFormatNames[format]
+	}
+	return "unknown"
+}
+
+// ParseExportFormat export format of the name, like 'csv', 'jsonl', 'json'
+// or 'xml'
+func ParseExportFormat(name string) (ExportFormat, error) {
+	for i, n := range exportFormatNames {
+		if strings.EqualFold(n, name) {
+			return ExportFormat(i), nil
+		}
+	}
+	return 0, errorrepo.NewError("DB000058", name)
+}
+
+// ExportOptions options of the record export
+type ExportOptions struct {
+	// NullValue text written for NULL values in CSV and XML
+	NullValue string
+	// OmitNull omit NULL values in JSON and XML records
+	OmitNull bool
+	// Comma field delimiter of CSV, default is ','
+	Comma rune
+}
+
+// Exporter write query results in the export format. The Write method is a
+// result function streaming each record to the writer.
+type Exporter struct {
+	writer  recordWriter
+	fields  []string
+	started bool
+	Counter uint64
+}
+
+// recordWriter format specific writer of records
+type recordWriter interface {
+	begin(fields []string) error
+	record(fields []string, values []any) error
+	end() error
+}
+
+// NewExporter new exporter writing records in the format to the writer
+func NewExporter(format ExportFormat, w io.Writer, options *ExportOptions) (*Exporter, error) {
+	if options == nil {
+		options = &ExportOptions{}
+	}
+	var rw recordWriter
+	switch format {
+	case CSVExport:
+		cw := csv.NewWriter(w)
+		if options.Comma != 0 {
+			cw.Comma = options.Comma
+		}
+		rw = &csvWriter{w: cw, options: options}
+	case JSONLinesExport:
+		rw = &jsonWriter{w: w, options: options}
+	case JSONExport:
+		rw = &jsonWriter{w: w, options: options, array: true}
+	case XMLExport:
+		rw = &xmlWriter{w: w, options: options}
+	default:
+		return nil, errorrepo.NewError("DB000058", format.String())
+	}
+	return &Exporter{writer: rw}, nil
+}
+
+// Export query records and write them in the export format to the writer.
+// Records are written while they are read. The number of records exported
+// is returned.
+func (id RegDbID) Export(query *Query, format ExportFormat, w io.Writer, options *ExportOptions) (uint64, error) {
+	exporter, err := NewExporter(format, w, options)
+	if err != nil {
+		return 0, err
+	}
+	_, err = id.Query(query, exporter.Write)
+	if err != nil {
+		return exporter.Counter, err
+	}
+	return exporter.Counter, exporter.Close()
+}
+
+// Write write the record of the result, usable as result function
+func (exporter *Exporter) Write(search *Query, result *Result) error {
+	fields, values, err := exportValues(search, result)
+	if err != nil {
+		return err
+	}
+	if !exporter.started {
+		exporter.fields = fields
+		exporter.started = true
+		err = exporter.writer.begin(fields)
+		if err != nil {
+			return err
+		}
+	}
+	exporter.Counter++
+	return exporter.writer.record(exporter.fields, values)
+}
+
+// Close finish the export writing the end of the document
+func (exporter *Exporter) Close() error {
+	if !exporter.started {
+		exporter.started = true
+		err := exporter.writer.begin(nil)
+		if err != nil {
+			return err
+		}
+	}
+	log.Log.Debugf("Exported %d records", exporter.Counter)
+	return exporter.writer.end()
+}
+
+// exportValues field names and values of the result record
+func exportValues(search *Query, result *Result) ([]string, []any, error) {
+	if search.DataStruct == nil || result.Data == nil {
+		fields := result.Fields
+		if len(fields) == 0 {
+			for _, h := range result.Header {
+				fields = append(fields, h.Name)
+			}
+		}
+		return fields, result.Rows, nil
+	}
+	fields := search.Fields
+	if len(fields) == 0 {
+		fields = []string{"*"}
+	}
+	value, _ := addressable(result.Data)
+	dynamic := CreateInterfaceNaming(value, fields, search.Naming)
+	values, err := dynamic.CreateValues(value)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dynamic.RowFields, values, nil
+}
+
+// exportValue plain value of the field value, NULL is returned as nil
+func exportValue(v any) (any, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		dv, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		v = dv
+	}
+	rv := reflect.ValueOf(v)
+	for rv.IsValid() && rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+		v = rv.Interface()
+	}
+	return v, nil
+}
+
+// exportText text representation of a value, time as RFC 3339 and binary
+// data base64 encoded
+func exportText(v any) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case []byte:
+		return base64.StdEncoding.EncodeToString(value)
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	case float32:
+		return strconv.FormatFloat(float64(value), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// csvWriter write records as CSV lines
+type csvWriter struct {
+	w       *csv.Writer
+	options *ExportOptions
+	line    []string
+}
+
+func (cw *csvWriter) begin(fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return cw.w.Write(fields)
+}
+
+func (cw *csvWriter) record(fields []string, values []any) error {
+	cw.line = cw.line[:0]
+	for _, v := range values {
+		v, err := exportValue(v)
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			cw.line = append(cw.line, cw.options.NullValue)
+			continue
+		}
+		cw.line = append(cw.line, exportText(v))
+	}
+	return cw.w.Write(cw.line)
+}
+
+func (cw *csvWriter) end() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// jsonWriter write records as JSON objects, one per line or as indented
+// JSON array
+type jsonWriter struct {
+	w       io.Writer
+	options *ExportOptions
+	array   bool
+	count   int
+	buffer  bytes.Buffer
+}
+
+func (jw *jsonWriter) begin(fields []string) error {
+	if jw.array {
+		_, err := io.WriteString(jw.w, "[")
+		return err
+	}
+	return nil
+}
+
+func (jw *jsonWriter) record(fields []string, values []any) error {
+	jw.buffer.Reset()
+	jw.buffer.WriteByte('{')
+	first := true
+	for i, v := range values {
+		v, err := exportValue(v)
+		if err != nil {
+			return err
+		}
+		if v == nil && jw.options.OmitNull {
+			continue
+		}
+		if t, ok := v.(time.Time); ok {
+			v = t.Format(time.RFC3339Nano)
+		}
+		if !first {
+			jw.buffer.WriteByte(',')
+		}
+		first = false
+		err = jw.encode(fields[i])
+		if err != nil {
+			return err
+		}
+		jw.buffer.WriteByte(':')
+		err = jw.encode(v)
+		if err != nil {
+			return err
+		}
+	}
+	jw.buffer.WriteByte('}')
+	if !jw.array {
+		jw.buffer.WriteByte('\n')
+		_, err := jw.w.Write(jw.buffer.Bytes())
+		return err
+	}
+	var indented bytes.Buffer
+	if jw.count > 0 {
+		indented.WriteByte(',')
+	}
+	indented.WriteString("\n  ")
+	err := json.Indent(&indented, jw.buffer.Bytes(), "  ", "  ")
+	if err != nil {
+		return err
+	}
+	jw.count++
+	_, err = jw.w.Write(indented.Bytes())
+	return err
+}
+
+// encode append JSON encoding of the value without HTML escaping
+func (jw *jsonWriter) encode(v any) error {
+	encoder := json.NewEncoder(&jw.buffer)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(v)
+	if err != nil {
+		return err
+	}
+	jw.buffer.Truncate(jw.buffer.Len() - 1)
+	return nil
+}
+
+func (jw *jsonWriter) end() error {
+	if !jw.array {
+		return nil
+	}
+	end := "\n]\n"
+	if jw.count == 0 {
+		end = "]\n"
+	}
+	_, err := io.WriteString(jw.w, end)
+	return err
+}
+
+// xmlWriter write records as XML elements of the records document
+type xmlWriter struct {
+	w       io.Writer
+	options *ExportOptions
+	buffer  bytes.Buffer
+	names   []string
+}
+
+func (xw *xmlWriter) begin(fields []string) error {
+	xw.names = make([]string, len(fields))
+	for i, f := range fields {
+		xw.names[i] = xmlName(f)
+	}
+	_, err := io.WriteString(xw.w, xml.Header+"<Records>\n")
+	return err
+}
+
+func (xw *xmlWriter) record(fields []string, values []any) error {
+	xw.buffer.Reset()
+	xw.buffer.WriteString("  <Record>")
+	for i, v := range values {
+		v, err := exportValue(v)
+		if err != nil {
+			return err
+		}
+		text := xw.options.NullValue
+		if v == nil {
+			if xw.options.OmitNull {
+				continue
+			}
+		} else {
+			text = exportText(v)
+		}
+		xw.buffer.WriteString("<" + xw.names[i] + ">")
+		err = xml.EscapeText(&xw.buffer, []byte(text))
+		if err != nil {
+			return err
+		}
+		xw.buffer.WriteString("</" + xw.names[i] + ">")
+	}
+	xw.buffer.WriteString("</Record>\n")
+	_, err := xw.w.Write(xw.buffer.Bytes())
+	return err
+}
+
+// xmlName valid XML element name of the field, characters not allowed are
+// replaced by '_' and names not starting with a letter get a '_' prefix
+func xmlName(field string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '_', r == '-', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, field)
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) && name[0] != '_' ||
+		strings.HasPrefix(strings.ToLower(name), "xml") {
+		name = "_" + name
+	}
+	return name
+}
+
+func (xw *xmlWriter) end() error {
+	_, err := io.WriteString(xw.w, "</Records>\n")
+	return err
+}
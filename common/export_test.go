@@ -0,0 +1,166 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type exportRecord struct {
+	Name  string
+	Count int64
+	Data  []byte
+	Note  sql.NullString
+	Time  time.Time
+}
+
+func exportRows(t *testing.T, format ExportFormat, options *ExportOptions) string {
+	var buffer bytes.Buffer
+	exporter, err := NewExporter(format, &buffer, options)
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	search := &Query{Fields: []string{"name", "count"}}
+	ts := time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)
+	for _, rows := range [][]any{
+		{"a,b", int64(1), []byte("xyz"), nil, ts},
+		{"<c>", int32(2), []byte{}, "note", ts},
+	} {
+		err = exporter.Write(search, &Result{Fields: []string{"name", "count", "data", "note", "time"}, Rows: rows})
+		if !assert.NoError(t, err) {
+			return ""
+		}
+	}
+	assert.NoError(t, exporter.Close())
+	assert.Equal(t, uint64(2), exporter.Counter)
+	return buffer.String()
+}
+
+func TestExportRows(t *testing.T) {
+	InitLog(t)
+
+	assert.Equal(t, "name,count,data,note,time\n"+
+		"\"a,b\",1,eHl6,NULL,2024-02-03T04:05:06Z\n"+
+		"<c>,2,,note,2024-02-03T04:05:06Z\n", exportRows(t, CSVExport, &ExportOptions{NullValue: "NULL"}))
+	assert.Equal(t, "name;count;data;note;time\n"+
+		"a,b;1;eHl6;;2024-02-03T04:05:06Z\n"+
+		"<c>;2;;note;2024-02-03T04:05:06Z\n", exportRows(t, CSVExport, &ExportOptions{Comma: ';'}))
+	assert.Equal(t, `{"name":"a,b","count":1,"data":"eHl6","note":null,"time":"2024-02-03T04:05:06Z"}`+"\n"+
+		`{"name":"<c>","count":2,"data":"","note":"note","time":"2024-02-03T04:05:06Z"}`+"\n",
+		exportRows(t, JSONLinesExport, nil))
+	assert.Equal(t, "[\n  {\n    \"name\": \"a,b\",\n    \"count\": 1,\n    \"data\": \"eHl6\",\n"+
+		"    \"time\": \"2024-02-03T04:05:06Z\"\n  },\n"+
+		"  {\n    \"name\": \"<c>\",\n    \"count\": 2,\n    \"data\": \"\",\n"+
+		"    \"note\": \"note\",\n    \"time\": \"2024-02-03T04:05:06Z\"\n  }\n]\n",
+		exportRows(t, JSONExport, &ExportOptions{OmitNull: true}))
+	assert.Equal(t, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<Records>\n"+
+		"  <Record><name>a,b</name><count>1</count><data>eHl6</data><time>2024-02-03T04:05:06Z</time></Record>\n"+
+		"  <Record><name>&lt;c&gt;</name><count>2</count><data></data><note>note</note><time>2024-02-03T04:05:06Z</time></Record>\n"+
+		"</Records>\n", exportRows(t, XMLExport, &ExportOptions{OmitNull: true}))
+}
+
+// exportFailValuer valuer failing to provide its value
+type exportFailValuer struct{}
+
+func (exportFailValuer) Value() (driver.Value, error) {
+	return nil, fmt.Errorf("value not available")
+}
+
+func TestExportXMLNames(t *testing.T) {
+	InitLog(t)
+
+	assert.Equal(t, "name", xmlName("name"))
+	assert.Equal(t, "FIRST-NAME", xmlName("FIRST-NAME"))
+	assert.Equal(t, "COUNT___", xmlName("COUNT(*)"))
+	assert.Equal(t, "first_name", xmlName("first name"))
+	assert.Equal(t, "_1st", xmlName("1st"))
+	assert.Equal(t, "_xmlData", xmlName("xmlData"))
+	assert.Equal(t, "_", xmlName(""))
+
+	var buffer bytes.Buffer
+	exporter, err := NewExporter(XMLExport, &buffer, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	err = exporter.Write(&Query{}, &Result{Fields: []string{"COUNT(*)", "a<b"}, Rows: []any{int64(1), "x"}})
+	assert.NoError(t, err)
+	assert.NoError(t, exporter.Close())
+	assert.Equal(t, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<Records>\n"+
+		"  <Record><COUNT___>1</COUNT___><a_b>x</a_b></Record>\n</Records>\n", buffer.String())
+}
+
+func TestExportValuerError(t *testing.T) {
+	InitLog(t)
+
+	for _, format := range []ExportFormat{CSVExport, JSONLinesExport, XMLExport} {
+		var buffer bytes.Buffer
+		exporter, err := NewExporter(format, &buffer, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		err = exporter.Write(&Query{}, &Result{Fields: []string{"v"}, Rows: []any{exportFailValuer{}}})
+		assert.EqualError(t, err, "value not available")
+	}
+}
+
+func TestExportStruct(t *testing.T) {
+	InitLog(t)
+
+	var buffer bytes.Buffer
+	exporter, err := NewExporter(JSONLinesExport, &buffer, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	search := &Query{DataStruct: &exportRecord{}, Fields: []string{"*"}}
+	ts := time.Date(2024, 2, 3, 4, 5, 6, 0, time.FixedZone("CET", 3600))
+	err = exporter.Write(search, &Result{Data: &exportRecord{Name: "abc", Count: 3, Data: []byte{0xff}, Time: ts}})
+	assert.NoError(t, err)
+	err = exporter.Write(search, &Result{Data: &exportRecord{Name: "def",
+		Note: sql.NullString{String: "n", Valid: true}, Time: ts}})
+	assert.NoError(t, err)
+	assert.NoError(t, exporter.Close())
+	assert.Equal(t, `{"Name":"abc","Count":3,"Data":"/w==","Note":null,"Time":"2024-02-03T04:05:06+01:00"}`+"\n"+
+		`{"Name":"def","Count":0,"Data":null,"Note":"n","Time":"2024-02-03T04:05:06+01:00"}`+"\n", buffer.String())
+}
+
+func TestExportEmpty(t *testing.T) {
+	InitLog(t)
+
+	for _, test := range []struct {
+		format   ExportFormat
+		expected string
+	}{
+		{CSVExport, ""},
+		{JSONLinesExport, ""},
+		{JSONExport, "[]\n"},
+		{XMLExport, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<Records>\n</Records>\n"},
+	} {
+		var buffer bytes.Buffer
+		exporter, err := NewExporter(test.format, &buffer, nil)
+		if assert.NoError(t, err) {
+			assert.NoError(t, exporter.Close())
+			assert.Equal(t, test.expected, buffer.String(), test.format.String())
+		}
+	}
+	format, err := ParseExportFormat("JSONL")
+	assert.NoError(t, err)
+	assert.Equal(t, JSONLinesExport, format)
+	_, err = ParseExportFormat("yaml")
+	assert.EqualError(t, err, "DB000058: export format yaml not valid")
+}
@@ -0,0 +1,100 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"context"
+
+	"github.com/tknie/log"
+)
+
+// BeforeInsertHook called for each structure value before it is inserted,
+// an error aborts the insert
+type BeforeInsertHook interface {
+	BeforeInsert(ctx context.Context, id RegDbID) error
+}
+
+// AfterInsertHook called for each structure value after it is inserted
+type AfterInsertHook interface {
+	AfterInsert(ctx context.Context, id RegDbID) error
+}
+
+// BeforeUpdateHook called for each structure value before it is updated,
+// an error aborts the update
+type BeforeUpdateHook interface {
+	BeforeUpdate(ctx context.Context, id RegDbID) error
+}
+
+// AfterUpdateHook called for each structure value after it is updated
+type AfterUpdateHook interface {
+	AfterUpdate(ctx context.Context, id RegDbID) error
+}
+
+// BeforeDeleteHook called on the structure of the delete entries before
+// records are deleted, an error aborts the delete
+type BeforeDeleteHook interface {
+	BeforeDelete(ctx context.Context, id RegDbID) error
+}
+
+// AfterQueryHook called for each structure record read by a query before
+// the result function is called, an error aborts the query
+type AfterQueryHook interface {
+	AfterQuery(ctx context.Context, id RegDbID) error
+}
+
+// structValues structure values of insert or update entries
+func (entries *Entries) structValues() []any {
+	if entries.DataStruct == nil {
+		return nil
+	}
+	values := make([]any, 0, len(entries.Values))
+	for _, v := range entries.Values {
+		if len(v) > 0 {
+			values = append(values, v[0])
+		}
+	}
+	return values
+}
+
+// callHooks call hook of all values implementing the hook interface T,
+// first error returned by a hook is returned
+func callHooks[T any](values []any, hook func(T) error) error {
+	for _, v := range values {
+		if h, ok := v.(T); ok {
+			err := hook(h)
+			if err != nil {
+				log.Log.Debugf("Hook %T returned error: %v", v, err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// afterQueryFunction result function binding lazy LOBs and calling the
+// AfterQuery hook of the structure record before the given result function
+func afterQueryFunction(ctx context.Context, id RegDbID, f ResultFunction) ResultFunction {
+	if f == nil {
+		return nil
+	}
+	return func(search *Query, result *Result) error {
+		bindLazyLOBs(id, search, result.Data)
+		if h, ok := result.Data.(AfterQueryHook); ok && search.DataStruct != nil {
+			err := h.AfterQuery(ctx, id)
+			if err != nil {
+				log.Log.Debugf("AfterQuery hook returned error: %v", err)
+				return err
+			}
+		}
+		return f(search, result)
+	}
+}
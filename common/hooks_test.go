@@ -0,0 +1,153 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// hookDatabase database driver recording calls of hook tests
+type hookDatabase struct {
+	Database
+	id     RegDbID
+	calls  []string
+	update *Entries
+}
+
+func (hd *hookDatabase) ID() RegDbID { return hd.id }
+
+func (hd *hookDatabase) Used() {}
+
+func (hd *hookDatabase) Insert(name string, insert *Entries) ([][]any, error) {
+	hd.calls = append(hd.calls, "insert")
+	return nil, nil
+}
+
+func (hd *hookDatabase) Update(name string, insert *Entries) ([][]any, int64, error) {
+	hd.calls = append(hd.calls, "update")
+	hd.update = insert
+	return nil, int64(len(insert.Values)), nil
+}
+
+func (hd *hookDatabase) Delete(name string, remove *Entries) (int64, error) {
+	hd.calls = append(hd.calls, "delete")
+	if remove.HardDelete {
+		hd.calls = append(hd.calls, "purge")
+	}
+	return 1, nil
+}
+
+func (hd *hookDatabase) Query(search *Query, f ResultFunction) (*Result, error) {
+	hd.calls = append(hd.calls, "query")
+	result := &Result{}
+	for i := 0; i < 2; i++ {
+		result.Data = &hookRecord{Name: fmt.Sprintf("rec%d", i)}
+		err := f(search, result)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+type hookRecord struct {
+	Name  string
+	Fail  bool
+	Calls []string `flynn:":ignore"`
+}
+
+func (hr *hookRecord) call(name string) error {
+	hr.Calls = append(hr.Calls, name)
+	if hr.Fail {
+		return fmt.Errorf("%s failed", name)
+	}
+	return nil
+}
+
+func (hr *hookRecord) BeforeInsert(ctx context.Context, id RegDbID) error {
+	return hr.call("BeforeInsert")
+}
+
+func (hr *hookRecord) AfterInsert(ctx context.Context, id RegDbID) error {
+	return hr.call("AfterInsert")
+}
+
+func (hr *hookRecord) BeforeUpdate(ctx context.Context, id RegDbID) error {
+	return hr.call("BeforeUpdate")
+}
+
+func (hr *hookRecord) AfterUpdate(ctx context.Context, id RegDbID) error {
+	return hr.call("AfterUpdate")
+}
+
+func (hr *hookRecord) BeforeDelete(ctx context.Context, id RegDbID) error {
+	return hr.call("BeforeDelete")
+}
+
+func (hr *hookRecord) AfterQuery(ctx context.Context, id RegDbID) error {
+	return hr.call("AfterQuery " + hr.Name)
+}
+
+func TestHooks(t *testing.T) {
+	InitLog(t)
+
+	hd := &hookDatabase{id: RegDbID(4712)}
+	registerTestDatabase(t, hd)
+	id := hd.id
+
+	r1, r2 := &hookRecord{Name: "a"}, &hookRecord{Name: "b"}
+	_, err := id.Insert("hooks", &Entries{Fields: []string{"*"}, DataStruct: r1, Values: [][]any{{r1}, {r2}}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BeforeInsert", "AfterInsert"}, r1.Calls)
+	assert.Equal(t, []string{"BeforeInsert", "AfterInsert"}, r2.Calls)
+
+	r2.Calls = nil
+	_, n, err := id.Update("hooks", &Entries{Fields: []string{"*"}, DataStruct: r2, Values: [][]any{{r2}}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.Equal(t, []string{"BeforeUpdate", "AfterUpdate"}, r2.Calls)
+
+	_, err = id.Delete("hooks", &Entries{Fields: []string{"Name"}, DataStruct: r2, Values: [][]any{{"b"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BeforeUpdate", "AfterUpdate", "BeforeDelete"}, r2.Calls)
+
+	names := make([]string, 0)
+	_, err = id.Query(&Query{TableName: "hooks", DataStruct: &hookRecord{}, Fields: []string{"*"}},
+		func(search *Query, result *Result) error {
+			hr := result.Data.(*hookRecord)
+			names = append(names, hr.Calls...)
+			return nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"AfterQuery rec0", "AfterQuery rec1"}, names)
+	assert.Equal(t, []string{"insert", "update", "delete", "query"}, hd.calls)
+
+	hd.calls = nil
+	failing := &hookRecord{Name: "c", Fail: true}
+	_, err = id.Insert("hooks", &Entries{Fields: []string{"*"}, DataStruct: failing, Values: [][]any{{r1}, {failing}}})
+	assert.EqualError(t, err, "BeforeInsert failed")
+	_, _, err = id.Update("hooks", &Entries{Fields: []string{"*"}, DataStruct: failing, Values: [][]any{{failing}}})
+	assert.Error(t, err)
+	_, err = id.Delete("hooks", &Entries{DataStruct: failing})
+	assert.Error(t, err)
+	assert.Empty(t, hd.calls)
+
+	remove := &Entries{Fields: []string{"Name"}, Values: [][]any{{"b"}}}
+	_, err = id.Purge("hooks", remove)
+	assert.NoError(t, err)
+	assert.False(t, remove.HardDelete)
+	assert.Equal(t, []string{"delete", "purge"}, hd.calls)
+}
@@ -0,0 +1,170 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/tknie/errorrepo"
+	"github.com/tknie/log"
+)
+
+// LazyLOB LOB field of a mapped structure read on demand. Queries do not
+// read the LOB content but reference the record by the key field of the
+// structure, the content is read using OpenLOB when needed. LazyLOB fields
+// are not written on insert or update, the content is stored using StreamWrite.
+type LazyLOB struct {
+	id      RegDbID
+	query   *Query
+	err     error
+	size    int64
+	hasSize bool
+	data    []byte
+	loaded  bool
+}
+
+var lazyLOBType = reflect.TypeOf(LazyLOB{})
+
+// IsLazyLOBType check if the type is LazyLOB or a pointer to LazyLOB
+func IsLazyLOBType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t == lazyLOBType
+}
+
+// Size size of the LOB content in bytes
+func (lob *LazyLOB) Size() (int64, error) {
+	if lob.loaded {
+		return int64(len(lob.data)), nil
+	}
+	if lob.hasSize {
+		return lob.size, nil
+	}
+	reader, err := lob.Reader()
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+	lob.size = reader.Size()
+	lob.hasSize = true
+	return lob.size, nil
+}
+
+// Bytes read complete LOB content, the content is kept for further calls
+func (lob *LazyLOB) Bytes() ([]byte, error) {
+	if lob.loaded {
+		return lob.data, nil
+	}
+	reader, err := lob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	lob.data = data
+	lob.loaded = true
+	return data, nil
+}
+
+// Reader open reader of the LOB content, the reader needs to be closed
+func (lob *LazyLOB) Reader() (LOBReader, error) {
+	if lob.loaded {
+		r := bytes.NewReader(lob.data)
+		return NewLOBReader(r.Size(), 0, func(offset int64, length int32) ([]byte, error) {
+			block := make([]byte, length)
+			n, err := r.ReadAt(block, offset)
+			if err == io.EOF {
+				err = nil
+			}
+			return block[:n], err
+		}, nil), nil
+	}
+	if lob.err != nil {
+		return nil, lob.err
+	}
+	if lob.query == nil {
+		return nil, errorrepo.NewError("DB000052")
+	}
+	q := *lob.query
+	return lob.id.OpenLOB(&q)
+}
+
+// bindLazyLOBs set the record reference of all LazyLOB fields of the
+// queried structure value
+func bindLazyLOBs(id RegDbID, search *Query, data any) {
+	if search.DataStruct == nil || data == nil {
+		return
+	}
+	dynamic := CreateInterfaceNaming(search.DataStruct, search.Fields, search.Naming)
+	columns := dynamic.RowNames["#lazylob"]
+	if len(columns) == 0 {
+		return
+	}
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	v = v.Elem()
+	condition, err := dynamic.keyCondition(v, search.TableName)
+	for _, c := range columns {
+		f, ok := dynamic.fieldByColumn(v, "", c)
+		if !ok {
+			continue
+		}
+		log.Log.Debugf("Bind lazy LOB %s to %s", c, condition)
+		lob := &LazyLOB{id: id, err: err, query: &Query{TableName: search.TableName,
+			Fields: []string{c}, Search: condition, Blocksize: search.Blocksize}}
+		if f.Kind() == reflect.Pointer {
+			f.Set(reflect.ValueOf(lob))
+		} else {
+			f.Set(reflect.ValueOf(lob).Elem())
+		}
+	}
+}
+
+// keyCondition search condition selecting the record of the structure
+// value using the key field
+func (dynamic *typeInterface) keyCondition(v reflect.Value, table string) (string, error) {
+	keys := dynamic.RowNames["#key"]
+	if len(keys) == 0 {
+		return "", errorrepo.NewError("DB000053", v.Type().Name(), table)
+	}
+	f, ok := dynamic.fieldByColumn(v, "", keys[0])
+	if !ok {
+		return "", errorrepo.NewError("DB000053", v.Type().Name(), table)
+	}
+	// key fields not selected by the query are not read
+	if !slices.ContainsFunc(dynamic.RowFields, func(field string) bool {
+		return strings.EqualFold(field, keys[0])
+	}) {
+		return "", errorrepo.NewError("DB000065", keys[0], table)
+	}
+	if f.Kind() == reflect.Pointer {
+		if f.IsNil() {
+			return keys[0] + " IS NULL", nil
+		}
+		f = f.Elem()
+	}
+	if f.Kind() == reflect.String {
+		return keys[0] + "='" + strings.ReplaceAll(f.String(), "'", "''") + "'", nil
+	}
+	return fmt.Sprintf("%s=%v", keys[0], f.Interface()), nil
+}
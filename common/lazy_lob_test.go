@@ -0,0 +1,145 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lobDatabase database driver returning one record with lazy LOB
+type lobDatabase struct {
+	Database
+	id      RegDbID
+	record  any
+	content []byte
+	opened  []*Query
+}
+
+func (ld *lobDatabase) ID() RegDbID { return ld.id }
+
+func (ld *lobDatabase) Used() {}
+
+func (ld *lobDatabase) Query(search *Query, f ResultFunction) (*Result, error) {
+	result := &Result{Data: ld.record}
+	return result, f(search, result)
+}
+
+func (ld *lobDatabase) OpenLOB(search *Query) (LOBReader, error) {
+	ld.opened = append(ld.opened, search)
+	return NewLOBReader(int64(len(ld.content)), search.Blocksize, func(offset int64, length int32) ([]byte, error) {
+		end := min(offset+int64(length), int64(len(ld.content)))
+		return ld.content[offset:end], nil
+	}, nil), nil
+}
+
+type lazyPicture struct {
+	Checksum string   `flynn:"checksum:key"`
+	Title    string   `flynn:"title"`
+	Media    LazyLOB  `flynn:"media"`
+	Thumb    *LazyLOB `flynn:"thumbnail"`
+}
+
+func TestLazyLOBFields(t *testing.T) {
+	InitLog(t)
+
+	ti := CreateInterface(&lazyPicture{}, []string{"*"})
+	assert.Equal(t, []string{"checksum", "title"}, ti.RowFields)
+	assert.Equal(t, []string{"media", "thumbnail"}, ti.RowNames["#lazylob"])
+	ti = CreateInterface(&lazyPicture{}, []string{"checksum", "media"})
+	assert.Equal(t, []string{"checksum"}, ti.RowFields)
+	assert.Equal(t, []string{"media"}, ti.RowNames["#lazylob"])
+
+	v := &lazyPicture{Checksum: "abc", Title: "x"}
+	values, err := ti.CreateValues(v)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"abc"}, values)
+
+	var lob LazyLOB
+	_, err = lob.Bytes()
+	assert.Error(t, err)
+}
+
+func TestLazyLOBQuery(t *testing.T) {
+	InitLog(t)
+
+	ld := &lobDatabase{id: RegDbID(4715), content: []byte("picture content"),
+		record: &lazyPicture{Checksum: "it's", Title: "title"}}
+	registerTestDatabase(t, ld)
+
+	var picture *lazyPicture
+	_, err := ld.id.Query(&Query{TableName: "pictures", DataStruct: &lazyPicture{}, Fields: []string{"*"},
+		Blocksize: 4}, func(search *Query, result *Result) error {
+		picture = result.Data.(*lazyPicture)
+		return nil
+	})
+	if !assert.NoError(t, err) || !assert.NotNil(t, picture) {
+		return
+	}
+	assert.Empty(t, ld.opened)
+	size, err := picture.Media.Size()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15), size)
+	if assert.Len(t, ld.opened, 1) {
+		assert.Equal(t, "pictures", ld.opened[0].TableName)
+		assert.Equal(t, []string{"media"}, ld.opened[0].Fields)
+		assert.Equal(t, "checksum='it''s'", ld.opened[0].Search)
+		assert.Equal(t, int32(4), ld.opened[0].Blocksize)
+	}
+	data, err := picture.Media.Bytes()
+	assert.NoError(t, err)
+	assert.Equal(t, "picture content", string(data))
+	reader, err := picture.Media.Reader()
+	if assert.NoError(t, err) {
+		data, err = io.ReadAll(io.NewSectionReader(reader, 8, 7))
+		assert.NoError(t, err)
+		assert.Equal(t, "content", string(data))
+		reader.Close()
+	}
+	assert.Len(t, ld.opened, 2)
+
+	if assert.NotNil(t, picture.Thumb) {
+		_, err = picture.Thumb.Bytes()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"thumbnail"}, ld.opened[2].Fields)
+	}
+
+	// key field not selected cannot reference the record
+	ld.opened = nil
+	_, err = ld.id.Query(&Query{TableName: "pictures", DataStruct: &lazyPicture{}, Fields: []string{"title", "media"}},
+		func(search *Query, result *Result) error {
+			picture = result.Data.(*lazyPicture)
+			return nil
+		})
+	if assert.NoError(t, err) {
+		_, err = picture.Media.Size()
+		assert.EqualError(t, err, "DB000065: lazy LOB needs key field checksum of pictures in query fields")
+		assert.Empty(t, ld.opened)
+	}
+
+	ld.record = &struct {
+		Title string  `flynn:"title"`
+		Media LazyLOB `flynn:"media"`
+	}{}
+	_, err = ld.id.Query(&Query{TableName: "pictures", DataStruct: ld.record, Fields: []string{"*"}},
+		func(search *Query, result *Result) error {
+			return nil
+		})
+	assert.NoError(t, err)
+	_, err = ld.record.(*struct {
+		Title string  `flynn:"title"`
+		Media LazyLOB `flynn:"media"`
+	}).Media.Size()
+	assert.Error(t, err)
+}
@@ -0,0 +1,166 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"io"
+	"sync"
+
+	"github.com/tknie/errorrepo"
+	"github.com/tknie/log"
+)
+
+// DefaultReadBlocksize block size of LOB reads if the query defines no
+// block size
+const DefaultReadBlocksize = 64 * 1024
+
+// LOBReader random access reader of a LOB field, data is read on demand
+// in blocks of the query block size
+type LOBReader interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+	io.Closer
+	// Size total size of the LOB in bytes
+	Size() int64
+}
+
+// RangeReader read length bytes of the LOB starting at the offset, less
+// bytes are returned at the end of the LOB
+type RangeReader func(offset int64, length int32) ([]byte, error)
+
+// lobReader LOB reader reading aligned blocks with the range reader, the
+// last block read is kept for following reads
+type lobReader struct {
+	lock        sync.Mutex
+	size        int64
+	blocksize   int32
+	position    int64
+	block       []byte
+	blockOffset int64
+	readRange   RangeReader
+	closer      func() error
+}
+
+// NewLOBReader create LOB reader of the given size using the range reader.
+// The close function is called on Close if set.
+func NewLOBReader(size int64, blocksize int32, readRange RangeReader, closer func() error) LOBReader {
+	if blocksize <= 0 {
+		blocksize = DefaultReadBlocksize
+	}
+	return &lobReader{size: size, blocksize: blocksize, blockOffset: -1,
+		readRange: readRange, closer: closer}
+}
+
+// Size total size of the LOB in bytes
+func (lr *lobReader) Size() int64 {
+	return lr.size
+}
+
+// Read read data at the current position
+func (lr *lobReader) Read(p []byte) (int, error) {
+	lr.lock.Lock()
+	defer lr.lock.Unlock()
+	n, err := lr.readAt(p, lr.position)
+	lr.position += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// ReadAt read data at the offset, the current position is not changed
+func (lr *lobReader) ReadAt(p []byte, off int64) (int, error) {
+	lr.lock.Lock()
+	defer lr.lock.Unlock()
+	return lr.readAt(p, off)
+}
+
+func (lr *lobReader) readAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errorrepo.NewError("DB000051", off)
+	}
+	n := 0
+	for n < len(p) && off+int64(n) < lr.size {
+		pos := off + int64(n)
+		blockOffset := pos - pos%int64(lr.blocksize)
+		block, err := lr.readBlock(blockOffset)
+		if err != nil {
+			return n, err
+		}
+		start := pos - blockOffset
+		if start >= int64(len(block)) {
+			return n, io.ErrUnexpectedEOF
+		}
+		n += copy(p[n:], block[start:])
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// readBlock read block at the aligned offset if not read before
+func (lr *lobReader) readBlock(offset int64) ([]byte, error) {
+	if lr.blockOffset == offset {
+		return lr.block, nil
+	}
+	log.Log.Debugf("Read LOB block at %d size %d", offset, lr.blocksize)
+	block, err := lr.readRange(offset, lr.blocksize)
+	if err != nil {
+		lr.blockOffset = -1
+		return nil, err
+	}
+	lr.block = block
+	lr.blockOffset = offset
+	return block, nil
+}
+
+// Seek set position of the next read
+func (lr *lobReader) Seek(offset int64, whence int) (int64, error) {
+	lr.lock.Lock()
+	defer lr.lock.Unlock()
+	switch whence {
+	case io.SeekCurrent:
+		offset += lr.position
+	case io.SeekEnd:
+		offset += lr.size
+	}
+	if offset < 0 {
+		return lr.position, errorrepo.NewError("DB000051", offset)
+	}
+	lr.position = offset
+	return offset, nil
+}
+
+// Close release resources of the reader
+func (lr *lobReader) Close() error {
+	lr.lock.Lock()
+	defer lr.lock.Unlock()
+	lr.block = nil
+	if lr.closer == nil {
+		return nil
+	}
+	closer := lr.closer
+	lr.closer = nil
+	return closer()
+}
+
+// OpenLOB open reader of the LOB field of the first record found by the
+// search, the query contains the table, search and LOB field like for Stream
+func (id RegDbID) OpenLOB(search *Query) (LOBReader, error) {
+	driver, err := searchDataDriver(id)
+	if err != nil {
+		return nil, err
+	}
+	return driver.OpenLOB(search)
+}
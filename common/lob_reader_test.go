@@ -0,0 +1,102 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testLOBReader(data []byte, reads *int) LOBReader {
+	closed := false
+	return NewLOBReader(int64(len(data)), 4, func(offset int64, length int32) ([]byte, error) {
+		*reads++
+		end := offset + int64(length)
+		if end > int64(len(data)) {
+			end = int64(len(data))
+		}
+		return data[offset:end], nil
+	}, func() error {
+		if closed {
+			return io.ErrClosedPipe
+		}
+		closed = true
+		return nil
+	})
+}
+
+func TestLOBReader(t *testing.T) {
+	InitLog(t)
+
+	data := []byte("0123456789abcdefghij")
+	reads := 0
+	lr := testLOBReader(data, &reads)
+	assert.Equal(t, int64(20), lr.Size())
+
+	all, err := io.ReadAll(lr)
+	assert.NoError(t, err)
+	assert.Equal(t, data, all)
+	assert.Equal(t, 5, reads)
+
+	p := make([]byte, 6)
+	n, err := lr.ReadAt(p, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, n)
+	assert.Equal(t, "789abc", string(p))
+	n, err = lr.ReadAt(p, 17)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, "hij", string(p[:n]))
+	n, err = lr.ReadAt(p, 20)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, n)
+	_, err = lr.ReadAt(p, -1)
+	assert.Error(t, err)
+
+	pos, err := lr.Seek(-5, io.SeekEnd)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15), pos)
+	n, err = lr.Read(p)
+	assert.NoError(t, err)
+	assert.Equal(t, "fghij", string(p[:n]))
+	_, err = lr.Read(p)
+	assert.Equal(t, io.EOF, err)
+	pos, err = lr.Seek(-3, io.SeekCurrent)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(17), pos)
+	_, err = lr.Seek(-1, io.SeekStart)
+	assert.Error(t, err)
+
+	assert.NoError(t, lr.Close())
+	assert.NoError(t, lr.Close())
+}
+
+func TestLOBReaderServeContent(t *testing.T) {
+	InitLog(t)
+
+	data := []byte("0123456789abcdefghij")
+	reads := 0
+	lr := testLOBReader(data, &reads)
+	defer lr.Close()
+
+	request := httptest.NewRequest(http.MethodGet, "/picture", nil)
+	request.Header.Set("Range", "bytes=5-9")
+	recorder := httptest.NewRecorder()
+	http.ServeContent(recorder, request, "picture.bin", time.Time{}, lr)
+	assert.Equal(t, http.StatusPartialContent, recorder.Code)
+	assert.Equal(t, "bytes 5-9/20", recorder.Header().Get("Content-Range"))
+	assert.Equal(t, "56789", recorder.Body.String())
+}
@@ -0,0 +1,112 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/tknie/errorrepo"
+)
+
+// NamingStrategy map Go field names to column names. It is used for all
+// fields without explicit name in the tag.
+type NamingStrategy func(fieldName string) string
+
+var (
+	// IdentityNaming use Go field name as column name
+	IdentityNaming NamingStrategy = func(fieldName string) string { return fieldName }
+	// LowerNaming use lower case Go field name as column name
+	LowerNaming NamingStrategy = strings.ToLower
+	// SnakeCaseNaming map Go field names like 'CreatedAt' to 'created_at'
+	SnakeCaseNaming NamingStrategy = snakeCase
+)
+
+var namingLock sync.RWMutex
+
+var namingStrategies = make(map[RegDbID]NamingStrategy)
+
+// ParseNamingStrategy naming strategy of the given name, valid are
+// 'identity', 'lower' and 'snake'
+func ParseNamingStrategy(name string) (NamingStrategy, error) {
+	switch strings.ToLower(name) {
+	case "", "identity":
+		return IdentityNaming, nil
+	case "lower":
+		return LowerNaming, nil
+	case "snake", "snake_case":
+		return SnakeCaseNaming, nil
+	default:
+		return nil, errorrepo.NewError("DB000046", name)
+	}
+}
+
+// ColumnName column name of the Go field name, no naming strategy keeps
+// the field name
+func (ns NamingStrategy) ColumnName(fieldName string) string {
+	if ns == nil {
+		return fieldName
+	}
+	return ns(fieldName)
+}
+
+// ColumnNames column names of the Go field names
+func (ns NamingStrategy) ColumnNames(fieldNames []string) []string {
+	if ns == nil {
+		return fieldNames
+	}
+	names := make([]string, len(fieldNames))
+	for i, f := range fieldNames {
+		names[i] = ns(f)
+	}
+	return names
+}
+
+// SetNamingStrategy set naming strategy used by all structure based
+// operations of the database handle
+func (id RegDbID) SetNamingStrategy(ns NamingStrategy) {
+	namingLock.Lock()
+	defer namingLock.Unlock()
+	if ns == nil {
+		delete(namingStrategies, id)
+		return
+	}
+	namingStrategies[id] = ns
+}
+
+// NamingStrategy naming strategy of the database handle, nil if the
+// field names are used
+func (id RegDbID) NamingStrategy() NamingStrategy {
+	namingLock.RLock()
+	defer namingLock.RUnlock()
+	return namingStrategies[id]
+}
+
+// snakeCase convert camel case name into snake case, acronyms like 'ID'
+// or 'HTTP' are kept together
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var buffer strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && runes[i-1] != '_' {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(unicode.IsUpper(prev) && nextLower) {
+				buffer.WriteRune('_')
+			}
+		}
+		buffer.WriteRune(unicode.ToLower(r))
+	}
+	return buffer.String()
+}
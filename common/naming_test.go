@@ -0,0 +1,81 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamingSnakeCase(t *testing.T) {
+	for name, expected := range map[string]string{
+		"CreatedAt":  "created_at",
+		"UserID":     "user_id",
+		"ID":         "id",
+		"HTTPServer": "http_server",
+		"Field1":     "field1",
+		"Top10List":  "top10_list",
+		"created_at": "created_at",
+		"Already_OK": "already_ok",
+	} {
+		assert.Equal(t, expected, SnakeCaseNaming.ColumnName(name), name)
+	}
+}
+
+func TestNamingStrategy(t *testing.T) {
+	InitLog(t)
+
+	ns, err := ParseNamingStrategy("snake")
+	assert.NoError(t, err)
+	assert.Equal(t, "first_name", ns.ColumnName("FirstName"))
+	ns, err = ParseNamingStrategy("LOWER")
+	assert.NoError(t, err)
+	assert.Equal(t, "firstname", ns.ColumnName("FirstName"))
+	_, err = ParseNamingStrategy("kebab")
+	assert.Error(t, err)
+	assert.Equal(t, "FirstName", NamingStrategy(nil).ColumnName("FirstName"))
+
+	id := RegDbID(4711)
+	assert.Nil(t, id.NamingStrategy())
+	id.SetNamingStrategy(strings.ToUpper)
+	assert.Equal(t, "NAME", id.NamingStrategy().ColumnName("Name"))
+	id.SetNamingStrategy(nil)
+	assert.Nil(t, id.NamingStrategy())
+}
+
+func TestNamingInterface(t *testing.T) {
+	InitLog(t)
+
+	type namingRecord struct {
+		UserID    int
+		FirstName string
+		Nick      string        `flynn:"NickName"`
+		Home      prefixAddress `flynn:"home:prefix=home_"`
+	}
+	v := &namingRecord{UserID: 1, FirstName: "A", Nick: "B", Home: prefixAddress{City: "C"}}
+	ti := CreateInterfaceNaming(v, []string{"*"}, SnakeCaseNaming)
+	assert.Equal(t, []string{"user_id", "first_name", "NickName", "home_street", "home_city"}, ti.RowFields)
+	assert.Equal(t, "user_id,first_name,NickName,home_street,home_city", ti.CreateQueryFields())
+
+	ti = CreateInterfaceNaming(v, []string{"FirstName", "user_id"}, SnakeCaseNaming)
+	assert.Equal(t, []string{"user_id", "first_name"}, ti.RowFields)
+	values, err := ti.CreateValues(v)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{1, "A"}, values)
+
+	q := &Query{TableName: "users", DataStruct: v, Fields: []string{"*"}, Naming: LowerNaming}
+	selectCmd, err := q.Select()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT userid,firstname,NickName,home_street,home_city FROM users tn", selectCmd)
+}
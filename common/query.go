@@ -38,6 +38,12 @@ type Query struct {
 	DataStruct   any
 	TypeInfo     any
 	FctParameter any
+	// Naming naming strategy of structure field names, the strategy of
+	// the database handle is used if not set
+	Naming NamingStrategy
+	// IncludeDeleted query soft deleted records of structures with soft
+	// delete field too
+	IncludeDeleted bool
 }
 
 type sqlInterface interface {
@@ -47,6 +53,7 @@ type sqlInterface interface {
 func (q *Query) Select() (string, error) {
 	log.Log.Debugf("Query select with type %s", q.Driver)
 	var selectCmd bytes.Buffer
+	softDelete := ""
 	switch {
 	case q.TableName == "":
 		log.Log.Debugf("Table name missing")
@@ -56,8 +63,12 @@ func (q *Query) Select() (string, error) {
 		if q.Descriptor {
 			selectCmd.WriteString("DISTINCT ")
 		}
-		ti := CreateInterface(q.DataStruct, q.Fields)
+		ti := CreateInterfaceNaming(q.DataStruct, q.Fields, q.Naming)
+		ti.NativeArrays = q.Driver == PostgresType
 		q.TypeInfo = ti
+		if fields := ti.AutoFields(SoftDeleteOption); len(fields) > 0 && !q.IncludeDeleted {
+			softDelete = fields[0]
+		}
 		selectCmd.WriteString(ti.CreateQueryFields())
 		selectCmd.WriteString(" FROM " + q.TableName + " tn")
 	default:
@@ -77,11 +88,27 @@ func (q *Query) Select() (string, error) {
 		}
 		selectCmd.WriteString(" FROM " + q.TableName + " tn")
 	}
-	if q.Search != "" {
-		selectCmd.WriteString(" WHERE " + q.Search)
-	}
+	condition := q.Search
 	if q.Join != "" {
-		selectCmd.WriteString(" LIKE " + q.Join)
+		condition += " LIKE " + q.Join
+	}
+	switch {
+	case q.Search != "" && softDelete != "":
+		// search is put in parentheses to restrict all of its
+		// alternatives to records not deleted
+		selectCmd.WriteString(" WHERE (" + condition + ")")
+	case q.Search != "":
+		selectCmd.WriteString(" WHERE " + condition)
+	case q.Join != "":
+		selectCmd.WriteString(condition)
+	}
+	if softDelete != "" {
+		if q.Search != "" {
+			selectCmd.WriteString(" AND ")
+		} else {
+			selectCmd.WriteString(" WHERE ")
+		}
+		selectCmd.WriteString(softDelete + " IS NULL")
 	}
 	if len(q.Group) > 0 {
 		selectCmd.WriteString(" GROUP BY ")
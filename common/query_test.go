@@ -13,6 +13,7 @@ package common
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -61,3 +62,40 @@ func TestQuery(t *testing.T) {
 	assert.Equal(t, "SELECT * FROM (SELECT field1,field2 FROM ABC tn WHERE id='10' ORDER BY aaa ASC,bbb ASC,dddd DESC) WHERE rownum < 10", selectCmd)
 
 }
+
+type softDeleteRecord struct {
+	ID      int
+	Name    string
+	Deleted *time.Time `flynn:"deleted_at:softdelete"`
+}
+
+func TestQuerySoftDelete(t *testing.T) {
+	InitLog(t)
+
+	q := Query{Driver: PostgresType, TableName: "ABC", DataStruct: &softDeleteRecord{},
+		Fields: []string{"ID", "Name"}}
+	selectCmd, err := q.Select()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT ID,Name FROM ABC tn WHERE deleted_at IS NULL", selectCmd)
+
+	q.Search = "id=10 OR id=20"
+	selectCmd, err = q.Select()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT ID,Name FROM ABC tn WHERE (id=10 OR id=20) AND deleted_at IS NULL", selectCmd)
+
+	q.Search = "name='a' OR name"
+	q.Join = "'b%'"
+	selectCmd, err = q.Select()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT ID,Name FROM ABC tn WHERE (name='a' OR name LIKE 'b%') AND deleted_at IS NULL", selectCmd)
+
+	q.IncludeDeleted = true
+	selectCmd, err = q.Select()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT ID,Name FROM ABC tn WHERE name='a' OR name LIKE 'b%'", selectCmd)
+
+	q.Search, q.Join = "id=10 OR id=20", ""
+	selectCmd, err = q.Select()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT ID,Name FROM ABC tn WHERE id=10 OR id=20", selectCmd)
+}
@@ -44,6 +44,19 @@ func (rt ReferenceType) String() string {
 	return referenceTypeName[rt]
 }
 
+// Placeholder SQL parameter placeholder of the given parameter index
+// (starting with 1) used by the database dialect
+func (rt ReferenceType) Placeholder(index int) string {
+	switch rt {
+	case PostgresType:
+		return "$" + strconv.Itoa(index)
+	case OracleType:
+		return ":" + strconv.Itoa(index)
+	default:
+		return "?"
+	}
+}
+
 type Reference struct {
 	Driver   ReferenceType
 	Host     string
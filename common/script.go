@@ -0,0 +1,244 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tknie/errorrepo"
+	"github.com/tknie/log"
+)
+
+// ScriptOptions options running SQL scripts
+type ScriptOptions struct {
+	// Transaction execute all statements in one transaction, the transaction
+	// is rolled back if any statement fails
+	Transaction bool
+	// ContinueOnError execute the remaining statements after a failed one,
+	// not used in a transaction which is rolled back anyway
+	ContinueOnError bool
+}
+
+// StatementResult report of one statement of the script
+type StatementResult struct {
+	Statement    string
+	RowsAffected int64
+	Duration     time.Duration
+	Err          error
+}
+
+// ScriptResult report of all executed statements of the script
+type ScriptResult struct {
+	Statements []*StatementResult
+	Failed     int
+}
+
+// RunScript split the SQL script into statements and execute them one by
+// one. Oracle scripts may contain PL/SQL blocks terminated by a line with a
+// slash. Without ContinueOnError or in a transaction the execution stops
+// at the first failed statement and its error is returned, otherwise an
+// error counting the failed statements is returned. The report contains all executed statements.
+func (id RegDbID) RunScript(script string, options *ScriptOptions) (*ScriptResult, error) {
+	if options == nil {
+		options = &ScriptOptions{}
+	}
+	statements := SplitStatements(script, id.DriverType() == OracleType)
+	result := &ScriptResult{Statements: make([]*StatementResult, 0, len(statements))}
+	if options.Transaction {
+		err := id.BeginTransaction()
+		if err != nil {
+			return nil, err
+		}
+	}
+	var firstErr error
+	for _, statement := range statements {
+		sr := &StatementResult{Statement: statement}
+		start := time.Now()
+		sr.RowsAffected, sr.Err = id.Execute(statement)
+		sr.Duration = time.Since(start)
+		result.Statements = append(result.Statements, sr)
+		if sr.Err != nil {
+			log.Log.Debugf("Script statement error: %v", sr.Err)
+			result.Failed++
+			if firstErr == nil {
+				firstErr = sr.Err
+			}
+			if !options.ContinueOnError || options.Transaction {
+				break
+			}
+		}
+	}
+	if options.Transaction {
+		if result.Failed > 0 {
+			err := id.Rollback()
+			if err != nil {
+				log.Log.Debugf("Script rollback error: %v", err)
+			}
+		} else {
+			err := id.Commit()
+			if err != nil {
+				return result, err
+			}
+		}
+	}
+	switch {
+	case result.Failed == 0:
+		return result, nil
+	case !options.ContinueOnError || options.Transaction:
+		return result, firstErr
+	default:
+		return result, errorrepo.NewError("DB000063", strconv.Itoa(result.Failed),
+			strconv.Itoa(len(result.Statements)))
+	}
+}
+
+// SplitStatements split SQL script into single statements separated by
+// semicolons outside of quotes, dollar quotes and comments. Statements
+// containing only comments are dropped. If plsql is set, a line containing
+// only a slash terminates the statement and PL/SQL blocks are only
+// terminated this way.
+func SplitStatements(script string, plsql bool) []string {
+	statements := make([]string, 0)
+	var current strings.Builder
+	content := false
+	add := func() {
+		if s := strings.TrimSpace(current.String()); s != "" && content {
+			statements = append(statements, s)
+		}
+		current.Reset()
+		content = false
+	}
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		if plsql && (i == 0 || script[i-1] == '\n') {
+			line, _, _ := strings.Cut(script[i:], "\n")
+			if strings.TrimSpace(line) == "/" {
+				add()
+				i += len(line)
+				continue
+			}
+		}
+		switch {
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			// line comments are dropped, the line end is kept
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end - 1
+			}
+			continue
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				end = len(script)
+			} else {
+				end += i + 4
+			}
+			current.WriteString(script[i:end])
+			i = end - 1
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			end := strings.IndexByte(script[i+1:], c)
+			if end < 0 {
+				end = len(script)
+			} else {
+				end += i + 2
+			}
+			current.WriteString(script[i:end])
+			content = true
+			i = end - 1
+			continue
+		case c == '$':
+			if tag := dollarTag(script[i:]); tag != "" {
+				end := strings.Index(script[i+len(tag):], tag)
+				if end < 0 {
+					end = len(script)
+				} else {
+					end += i + 2*len(tag)
+				}
+				current.WriteString(script[i:end])
+				content = true
+				i = end - 1
+				continue
+			}
+		case c == ';':
+			if plsql && isPLSQLBlock(current.String()) {
+				break
+			}
+			add()
+			continue
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			content = true
+		}
+		current.WriteByte(c)
+	}
+	add()
+	return statements
+}
+
+// dollarTag PostgreSQL dollar quote tag like '$$' or '$body$' at the start
+// of the text, empty if the text does not start with a tag
+func dollarTag(text string) string {
+	for i := 1; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '$':
+			return text[:i+1]
+		case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		case c >= '0' && c <= '9' && i > 1:
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// isPLSQLBlock check if the statement starts a PL/SQL block which may
+// contain semicolons
+func isPLSQLBlock(statement string) bool {
+	for {
+		statement = strings.TrimSpace(statement)
+		if !strings.HasPrefix(statement, "/*") {
+			break
+		}
+		_, rest, found := strings.Cut(statement[2:], "*/")
+		if !found {
+			return false
+		}
+		statement = rest
+	}
+	words := strings.Fields(strings.ToUpper(statement))
+	if len(words) == 0 {
+		return false
+	}
+	switch words[0] {
+	case "DECLARE", "BEGIN":
+		return true
+	case "CREATE":
+	default:
+		return false
+	}
+	for _, w := range words[1:] {
+		switch w {
+		case "OR", "REPLACE", "EDITIONABLE", "NONEDITIONABLE":
+		case "PROCEDURE", "FUNCTION", "PACKAGE", "TRIGGER", "TYPE", "LIBRARY":
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// scriptDatabase database driver recording executed script statements
+type scriptDatabase struct {
+	Database
+	id         RegDbID
+	driver     ReferenceType
+	calls      []string
+	statements []string
+}
+
+func (sd *scriptDatabase) ID() RegDbID { return sd.id }
+
+func (sd *scriptDatabase) Used() {}
+
+func (sd *scriptDatabase) DriverType() ReferenceType { return sd.driver }
+
+func (sd *scriptDatabase) Execute(statement string, args ...any) (int64, error) {
+	sd.statements = append(sd.statements, statement)
+	if strings.Contains(statement, "fail") {
+		return -1, fmt.Errorf("statement failed")
+	}
+	return int64(len(sd.statements)), nil
+}
+
+func (sd *scriptDatabase) BeginTransaction() error {
+	sd.calls = append(sd.calls, "begin")
+	return nil
+}
+
+func (sd *scriptDatabase) Commit() error {
+	sd.calls = append(sd.calls, "commit")
+	return nil
+}
+
+func (sd *scriptDatabase) Rollback() error {
+	sd.calls = append(sd.calls, "rollback")
+	return nil
+}
+
+func TestSplitStatements(t *testing.T) {
+	InitLog(t)
+
+	assert.Equal(t, []string{"CREATE TABLE x (id INTEGER)", "INSERT INTO x VALUES ('a;b', 'it''s')",
+		"SELECT \"c;d\", `e;f` FROM x /* g; */"},
+		SplitStatements("CREATE TABLE x (id INTEGER);\n-- drop; later\nINSERT INTO x VALUES ('a;b', 'it''s');;\n"+
+			"SELECT \"c;d\", `e;f` FROM x /* g; */", false))
+	assert.Empty(t, SplitStatements(" ;\n-- only comment\n/* block\n comment */;", false))
+
+	function := "CREATE FUNCTION f() RETURNS trigger AS $body$\nBEGIN\n  NEW.x := 'a;b'; RETURN NEW;\nEND;\n$body$ LANGUAGE plpgsql"
+	assert.Equal(t, []string{function, "SELECT $$a;b$$, $1"},
+		SplitStatements(function+";\nSELECT $$a;b$$, $1;", false))
+
+	procedure := "CREATE OR REPLACE PROCEDURE p AS\nBEGIN\n  UPDATE x SET id = 1;\nEND;"
+	block := "DECLARE\n  n NUMBER;\nBEGIN\n  n := 1;\nEND;"
+	assert.Equal(t, []string{"CREATE TABLE x (id NUMBER)", procedure, block, "DELETE FROM x"},
+		SplitStatements("CREATE TABLE x (id NUMBER);\n"+procedure+"\n/\n"+block+"\n  /  \nDELETE FROM x\n/\n", true))
+	assert.Equal(t, []string{"SELECT 4 / 2 FROM dual"}, SplitStatements("SELECT 4 / 2 FROM dual;", true))
+}
+
+func TestRunScript(t *testing.T) {
+	InitLog(t)
+
+	sd := &scriptDatabase{id: RegDbID(4716), driver: PostgresType}
+	registerTestDatabase(t, sd)
+	id := sd.id
+
+	result, err := id.RunScript("INSERT INTO x VALUES (1);\nINSERT INTO x VALUES (2);",
+		&ScriptOptions{Transaction: true})
+	assert.NoError(t, err)
+	if assert.Len(t, result.Statements, 2) {
+		assert.Equal(t, "INSERT INTO x VALUES (2)", result.Statements[1].Statement)
+		assert.Equal(t, int64(2), result.Statements[1].RowsAffected)
+		assert.NoError(t, result.Statements[1].Err)
+	}
+	assert.Equal(t, 0, result.Failed)
+	assert.Equal(t, []string{"begin", "commit"}, sd.calls)
+
+	sd.calls, sd.statements = nil, nil
+	result, err = id.RunScript("SELECT 1; SELECT fail; SELECT 3", &ScriptOptions{Transaction: true})
+	assert.EqualError(t, err, "statement failed")
+	assert.Len(t, result.Statements, 2)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, []string{"SELECT 1", "SELECT fail"}, sd.statements)
+	assert.Equal(t, []string{"begin", "rollback"}, sd.calls)
+
+	// a failed statement ends the transaction also if continue is set
+	sd.calls, sd.statements = nil, nil
+	result, err = id.RunScript("SELECT 1; SELECT fail; SELECT 3",
+		&ScriptOptions{Transaction: true, ContinueOnError: true})
+	assert.EqualError(t, err, "statement failed")
+	assert.Len(t, result.Statements, 2)
+	assert.Equal(t, []string{"SELECT 1", "SELECT fail"}, sd.statements)
+	assert.Equal(t, []string{"begin", "rollback"}, sd.calls)
+
+	sd.calls, sd.statements = nil, nil
+	result, err = id.RunScript("SELECT fail; SELECT 2; SELECT fail again", &ScriptOptions{ContinueOnError: true})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "DB000063")
+	assert.Len(t, result.Statements, 3)
+	assert.Equal(t, 2, result.Failed)
+	assert.NoError(t, result.Statements[1].Err)
+	assert.Empty(t, sd.calls)
+
+	sd.driver = OracleType
+	sd.statements = nil
+	_, err = id.RunScript("BEGIN\n  NULL;\nEND;\n/\n", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BEGIN\n  NULL;\nEND;"}, sd.statements)
+}
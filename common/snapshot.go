@@ -0,0 +1,153 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/tknie/errorrepo"
+	"github.com/tknie/log"
+)
+
+// Snapshot field values of a structure taken after loading, used to
+// update only the fields changed afterwards
+type Snapshot struct {
+	dataType reflect.Type
+	values   map[string]any
+}
+
+// TakeSnapshot take snapshot of all mapped field values of the structure
+func TakeSnapshot(value any) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	err := snapshot.take(value)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// take store field values of the structure in the snapshot
+func (snapshot *Snapshot) take(value any) error {
+	dynamic, values, err := snapshotValues(value)
+	if err != nil {
+		return err
+	}
+	snapshot.dataType = structType(value)
+	snapshot.values = make(map[string]any)
+	for i, f := range dynamic.RowFields {
+		snapshot.values[f] = values[i]
+	}
+	return nil
+}
+
+// Changed field names of the structure value differing from the snapshot.
+// Automatic update and version fields are added if any field changed,
+// fields set on creation only are never part of the list.
+func (snapshot *Snapshot) Changed(value any) ([]string, error) {
+	if t := structType(value); t != snapshot.dataType {
+		return nil, errorrepo.NewError("DB000049", snapshot.dataType, t)
+	}
+	dynamic, values, err := snapshotValues(value)
+	if err != nil {
+		return nil, err
+	}
+	changed := make([]string, 0)
+	for i, f := range dynamic.RowFields {
+		if slices.Contains(dynamic.AutoFields(AutoCreateOption), f) {
+			continue
+		}
+		if old, ok := snapshot.values[f]; !ok || !reflect.DeepEqual(old, values[i]) {
+			changed = append(changed, f)
+		}
+	}
+	if len(changed) > 0 {
+		for _, o := range []string{AutoUpdateOption, VersionOption} {
+			for _, f := range dynamic.AutoFields(o) {
+				if !slices.Contains(changed, f) {
+					changed = append(changed, f)
+				}
+			}
+		}
+	}
+	log.Log.Debugf("Changed fields: %v", changed)
+	return changed, nil
+}
+
+// snapshotValues field values of the structure. Pointers into the
+// structure are dereferenced and byte slices are copied to detect changes
+// made in place.
+func snapshotValues(value any) (*typeInterface, []any, error) {
+	value, _ = addressable(value)
+	dynamic := CreateInterface(value, []string{"*"})
+	values, err := dynamic.CreateValues(value)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, v := range values {
+		rv := reflect.ValueOf(v)
+		for rv.IsValid() && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				rv = reflect.Value{}
+				break
+			}
+			rv = rv.Elem()
+		}
+		if !rv.IsValid() {
+			values[i] = nil
+			continue
+		}
+		values[i] = rv.Interface()
+		if b, ok := values[i].([]byte); ok {
+			values[i] = bytes.Clone(b)
+		}
+	}
+	return dynamic, values, nil
+}
+
+// structType structure type of the value or value pointer
+func structType(value any) reflect.Type {
+	t := reflect.TypeOf(value)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// UpdateChanged update the fields of the structure value changed since
+// the snapshot was taken. The records are selected by the update
+// conditions or key field names like in Entries.Update. No statement is
+// sent if nothing changed, the snapshot is renewed after the update.
+func (id RegDbID) UpdateChanged(name string, snapshot *Snapshot, value any, update ...string) (int64, error) {
+	changed, err := snapshot.Changed(value)
+	if err != nil {
+		return 0, err
+	}
+	if len(changed) == 0 {
+		log.Log.Debugf("No fields changed, skip update of %s", name)
+		return 0, nil
+	}
+	fields := slices.Clone(changed)
+	for _, u := range update {
+		if !strings.ContainsAny(u, "=<>") && !slices.Contains(fields, u) {
+			fields = append(fields, u)
+		}
+	}
+	_, rowsAffected, err := id.Update(name, &Entries{Fields: fields, DataStruct: value,
+		Update: update, Values: [][]any{{value}}})
+	if err != nil {
+		return rowsAffected, err
+	}
+	return rowsAffected, snapshot.take(value)
+}
@@ -0,0 +1,112 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type snapshotRecord struct {
+	ID      int       `flynn:"id"`
+	Name    string    `flynn:"name"`
+	Data    []byte    `flynn:"data"`
+	When    time.Time `flynn:"when"`
+	Note    *string   `flynn:"note"`
+	Address prefixAddress
+}
+
+func TestSnapshotChanged(t *testing.T) {
+	InitLog(t)
+
+	v := &snapshotRecord{ID: 1, Name: "abc", Data: []byte{1, 2}, Address: prefixAddress{City: "Berlin"}}
+	snapshot, err := TakeSnapshot(v)
+	if !assert.NoError(t, err) {
+		return
+	}
+	changed, err := snapshot.Changed(v)
+	assert.NoError(t, err)
+	assert.Empty(t, changed)
+
+	v.Data[0] = 5
+	v.Address.City = "Hamburg"
+	changed, err = snapshot.Changed(v)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"data", "Address_city"}, changed)
+
+	v = &snapshotRecord{ID: 1, When: time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)}
+	snapshot, err = TakeSnapshot(v)
+	if !assert.NoError(t, err) {
+		return
+	}
+	v.When = v.When.Add(time.Hour)
+	note := "note"
+	v.Note = &note
+	changed, err = snapshot.Changed(v)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"when", "note"}, changed)
+	snapshot, err = TakeSnapshot(v)
+	if !assert.NoError(t, err) {
+		return
+	}
+	note = "changed"
+	changed, err = snapshot.Changed(v)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"note"}, changed)
+
+	_, err = snapshot.Changed(&autoRecord{})
+	assert.Error(t, err)
+
+	a := &autoRecord{ID: 1, Name: "abc"}
+	snapshot, err = TakeSnapshot(a)
+	if !assert.NoError(t, err) {
+		return
+	}
+	a.Name = "xyz"
+	changed, err = snapshot.Changed(*a)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Name", "modified", "version"}, changed)
+}
+
+func TestSnapshotUpdateChanged(t *testing.T) {
+	InitLog(t)
+
+	hd := &hookDatabase{id: RegDbID(4713)}
+	registerTestDatabase(t, hd)
+
+	v := &snapshotRecord{ID: 1, Name: "abc"}
+	snapshot, err := TakeSnapshot(v)
+	if !assert.NoError(t, err) {
+		return
+	}
+	n, err := hd.id.UpdateChanged("records", snapshot, v, "id")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+	assert.Empty(t, hd.calls)
+
+	v.Name = "xyz"
+	n, err = hd.id.UpdateChanged("records", snapshot, v, "id")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.Equal(t, []string{"update"}, hd.calls)
+	if assert.NotNil(t, hd.update) {
+		assert.Equal(t, []string{"name", "id"}, hd.update.Fields)
+		assert.Equal(t, []string{"id"}, hd.update.Update)
+	}
+
+	n, err = hd.id.UpdateChanged("records", snapshot, v, "id")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+	assert.Equal(t, []string{"update"}, hd.calls)
+}
@@ -0,0 +1,113 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"hash"
+	"io"
+	"unicode/utf8"
+
+	"github.com/tknie/log"
+)
+
+// DefaultWriteBlocksize block size of chunked LOB writes if the query
+// defines no block size
+const DefaultWriteBlocksize = 1024 * 1024
+
+// BlockFunction function called for each block read, offset is the
+// position of the block in the data
+type BlockFunction func(offset int64, block []byte) error
+
+// StreamBlocks read the reader in blocks of the block size and call the
+// block function for each block. At least one block is provided, it is
+// empty if the reader contains no data. The number of bytes read is returned.
+func StreamBlocks(reader io.Reader, blocksize int32, f BlockFunction) (int64, error) {
+	if blocksize <= 0 {
+		blocksize = DefaultWriteBlocksize
+	}
+	buffer := make([]byte, blocksize)
+	offset := int64(0)
+	for {
+		n, err := io.ReadFull(reader, buffer)
+		switch err {
+		case nil, io.ErrUnexpectedEOF:
+		case io.EOF:
+			if offset > 0 {
+				return offset, nil
+			}
+		default:
+			return offset, err
+		}
+		ferr := f(offset, buffer[:n])
+		if ferr != nil {
+			return offset, ferr
+		}
+		offset += int64(n)
+		if err != nil {
+			return offset, nil
+		}
+	}
+}
+
+// StreamTextBlocks read UTF-8 text of the reader in blocks like StreamBlocks,
+// blocks end at character boundaries. Incomplete characters at the end
+// of a block are moved to the next block, so a block may exceed the
+// block size by up to three bytes.
+func StreamTextBlocks(reader io.Reader, blocksize int32, f BlockFunction) (int64, error) {
+	var rest []byte
+	offset := int64(0)
+	n, err := StreamBlocks(reader, blocksize, func(_ int64, block []byte) error {
+		data := append(rest, block...)
+		cut := runeBoundary(data)
+		rest = append([]byte{}, data[cut:]...)
+		if cut == 0 && offset > 0 {
+			return nil
+		}
+		err := f(offset, data[:cut])
+		offset += int64(cut)
+		return err
+	})
+	if err == nil && len(rest) > 0 {
+		err = f(offset, rest)
+	}
+	return n, err
+}
+
+// runeBoundary length of the data up to the last complete UTF-8 character
+func runeBoundary(data []byte) int {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if utf8.FullRune(data[i:]) {
+				return len(data)
+			}
+			return i
+		}
+	}
+	return len(data)
+}
+
+// StreamWrite write the data of the reader into the LOB field of the
+// records matching the criteria. The field is set to the first block and
+// the following blocks are appended, so the data is never held in memory
+// completely. If the checksum is not nil, it is calculated of all data
+// written. The number of bytes written is returned.
+func (id RegDbID) StreamWrite(table, field, criteria string, reader io.Reader, checksum hash.Hash) (int64, error) {
+	driver, err := searchDataDriver(id)
+	if err != nil {
+		return 0, err
+	}
+	if checksum != nil {
+		reader = io.TeeReader(reader, checksum)
+	}
+	log.Log.Debugf("Stream write to %s in %s", field, table)
+	return driver.StreamWrite(&Query{TableName: table, Fields: []string{field}, Search: criteria}, reader)
+}
@@ -0,0 +1,134 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// streamDatabase database driver collecting stream written data
+type streamDatabase struct {
+	Database
+	id     RegDbID
+	search *Query
+	data   bytes.Buffer
+}
+
+func (sd *streamDatabase) ID() RegDbID { return sd.id }
+
+func (sd *streamDatabase) Used() {}
+
+func (sd *streamDatabase) StreamWrite(search *Query, reader io.Reader) (int64, error) {
+	sd.search = search
+	return StreamBlocks(reader, 3, func(offset int64, block []byte) error {
+		if offset == 0 {
+			sd.data.Reset()
+		}
+		sd.data.Write(block)
+		return nil
+	})
+}
+
+func TestStreamBlocks(t *testing.T) {
+	InitLog(t)
+
+	for _, test := range []struct {
+		data    string
+		offsets []int64
+	}{
+		{"", []int64{0}},
+		{"ab", []int64{0}},
+		{"abc", []int64{0}},
+		{"abcdefg", []int64{0, 3, 6}},
+	} {
+		offsets := make([]int64, 0)
+		var data bytes.Buffer
+		n, err := StreamBlocks(strings.NewReader(test.data), 3, func(offset int64, block []byte) error {
+			offsets = append(offsets, offset)
+			data.Write(block)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(test.data)), n)
+		assert.Equal(t, test.offsets, offsets, test.data)
+		assert.Equal(t, test.data, data.String())
+	}
+
+	n, err := StreamBlocks(strings.NewReader("abcdefg"), 3, func(offset int64, block []byte) error {
+		if offset > 0 {
+			return fmt.Errorf("abort")
+		}
+		return nil
+	})
+	assert.EqualError(t, err, "abort")
+	assert.Equal(t, int64(3), n)
+}
+
+func TestStreamTextBlocks(t *testing.T) {
+	InitLog(t)
+
+	for _, test := range []struct {
+		data   string
+		blocks []string
+	}{
+		{"", []string{""}},
+		{"abcdefg", []string{"abc", "def", "g"}},
+		{"aäöb", []string{"aä", "öb"}},
+		{"äöü", []string{"ä", "öü"}},
+		{"a€b", []string{"a", "€b"}},
+	} {
+		blocks := make([]string, 0)
+		offset := int64(0)
+		n, err := StreamTextBlocks(strings.NewReader(test.data), 3, func(o int64, block []byte) error {
+			assert.Equal(t, offset, o)
+			offset += int64(len(block))
+			blocks = append(blocks, string(block))
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(test.data)), n)
+		assert.Equal(t, test.blocks, blocks, test.data)
+	}
+	assert.Equal(t, 1, runeBoundary([]byte("a\xe2\x82")))
+	assert.Equal(t, 1, runeBoundary([]byte("a\xc3")))
+	assert.Equal(t, 4, runeBoundary([]byte("a\xe2\x82\xac")))
+}
+
+func TestStreamWrite(t *testing.T) {
+	InitLog(t)
+
+	sd := &streamDatabase{id: RegDbID(4714)}
+	registerTestDatabase(t, sd)
+
+	data := strings.Repeat("picture", 10)
+	checksum := sha256.New()
+	n, err := sd.id.StreamWrite("pictures", "media", "id=1", strings.NewReader(data), checksum)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(data)), n)
+	assert.Equal(t, data, sd.data.String())
+	assert.Equal(t, "pictures", sd.search.TableName)
+	assert.Equal(t, []string{"media"}, sd.search.Fields)
+	assert.Equal(t, "id=1", sd.search.Search)
+	sum := sha256.Sum256([]byte(data))
+	assert.Equal(t, sum[:], checksum.Sum(nil))
+
+	_, err = sd.id.StreamWrite("pictures", "media", "id=1", strings.NewReader("abc"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", sd.data.String())
+}
@@ -0,0 +1,319 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tknie/errorrepo"
+)
+
+// fieldMeta parsed tag information of a structure field
+type fieldMeta struct {
+	field        reflect.StructField
+	tag          string
+	tagName      string
+	tagInfo      TagInfo
+	converter    string
+	hasConverter bool
+	auto         string
+	lazy         bool
+}
+
+// fieldNamesKey cache key of generated field names
+type fieldNamesKey struct {
+	t      reflect.Type
+	fields string
+	naming string
+}
+
+// fieldNames generated field names of a structure type
+type fieldNames struct {
+	rowFields []string
+	rowNames  map[string][]string
+}
+
+// structMetaCache parsed field information per structure type
+var structMetaCache sync.Map
+
+// fieldNamesCache generated field names per structure type, field list
+// and naming strategy
+var fieldNamesCache sync.Map
+
+// structMetadata parsed tag information of all fields of the structure
+// type, the information is parsed once per type
+func structMetadata(t reflect.Type) []*fieldMeta {
+	if m, ok := structMetaCache.Load(t); ok {
+		return m.([]*fieldMeta)
+	}
+	meta := make([]*fieldMeta, t.NumField())
+	for i := range meta {
+		f := t.Field(i)
+		tag := f.Tag.Get(TagName)
+		fm := &fieldMeta{field: f, tag: tag, lazy: IsLazyLOBType(f.Type)}
+		fm.tagName, fm.tagInfo = TagInfoParse(tag)
+		fm.converter, fm.hasConverter = TagOption(tag, ConverterTag)
+		for _, o := range fieldOptions {
+			if _, ok := TagOption(tag, o); ok {
+				fm.auto = o
+			}
+		}
+		meta[i] = fm
+	}
+	m, _ := structMetaCache.LoadOrStore(t, meta)
+	return m.([]*fieldMeta)
+}
+
+// columnName column name of the field using the tag name or the mapped
+// Go field name
+func (fm *fieldMeta) columnName(naming NamingStrategy, prefix string) string {
+	if fm.tagName != "" {
+		return prefix + fm.tagName
+	}
+	return prefix + naming.ColumnName(fm.field.Name)
+}
+
+// fieldConverter converter referenced by the field, nil if the field
+// references no converter
+func (fm *fieldMeta) fieldConverter(fieldName string) (Converter, error) {
+	if !fm.hasConverter {
+		return nil, nil
+	}
+	c, ok := LookupConverter(fm.converter)
+	if !ok {
+		return nil, errorrepo.NewError("DB000044", fm.converter, fieldName)
+	}
+	return c, nil
+}
+
+// namingCacheKey cache key part of the naming strategy, only predefined
+// strategies can be cached because custom functions are not comparable
+func namingCacheKey(naming NamingStrategy) (string, bool) {
+	if naming == nil {
+		return "", true
+	}
+	switch reflect.ValueOf(naming).Pointer() {
+	case reflect.ValueOf(IdentityNaming).Pointer():
+		return "identity", true
+	case reflect.ValueOf(LowerNaming).Pointer():
+		return "lower", true
+	case reflect.ValueOf(SnakeCaseNaming).Pointer():
+		return "snake", true
+	default:
+		return "", false
+	}
+}
+
+// cachedFieldNames field names generated before for the structure type,
+// field list and naming strategy
+func cachedFieldNames(t reflect.Type, fields []string, naming NamingStrategy) (*fieldNames, fieldNamesKey, bool) {
+	n, ok := namingCacheKey(naming)
+	if !ok {
+		return nil, fieldNamesKey{}, false
+	}
+	key := fieldNamesKey{t: t, fields: strings.Join(fields, ","), naming: n}
+	if fn, ok := fieldNamesCache.Load(key); ok {
+		return fn.(*fieldNames), key, true
+	}
+	return nil, key, true
+}
+
+// storeFieldNames store copy of generated field names in cache
+func storeFieldNames(key fieldNamesKey, rowFields []string, rowNames map[string][]string) {
+	fn := &fieldNames{rowFields: slices.Clone(rowFields), rowNames: make(map[string][]string)}
+	for k, v := range rowNames {
+		fn.rowNames[k] = slices.Clone(v)
+	}
+	fieldNamesCache.Store(key, fn)
+}
+
+// copyFieldNames copy of the cached field names, the type interface may
+// change the lists
+func (fn *fieldNames) copyFieldNames() ([]string, map[string][]string) {
+	rowNames := make(map[string][]string)
+	for k, v := range fn.rowNames {
+		rowNames[k] = slices.Clone(v)
+	}
+	return slices.Clone(fn.rowFields), rowNames
+}
+
+// valueKind kind of value extraction of a planned field
+type valueKind byte
+
+const (
+	plainValue valueKind = iota
+	nilableValue
+	addressValue
+	pointerValue
+	valuerValue
+)
+
+// valueStep field index path and value extraction of one column
+type valueStep struct {
+	index []int
+	kind  valueKind
+}
+
+// valuePlan steps extracting the insert and update values of a structure
+// type without walking the structure for each record. Structures with
+// fields needing the generic value generation like converters, arrays or
+// encoded sub structures have no plan. Queries need no plan, they reuse
+// the scan targets created once per query for all records.
+type valuePlan struct {
+	steps   []valueStep
+	generic bool
+}
+
+// valuePlanKey cache key of value plans
+type valuePlanKey struct {
+	fieldNamesKey
+	excluded string
+}
+
+// valuePlanCache value plans per structure type, field list, naming
+// strategy and excluded fields
+var valuePlanCache sync.Map
+
+// cachedValuePlan value plan of the structure type, the plan is created
+// once per type and field set. Nil is returned if the generic value
+// generation is needed.
+func (dynamic *typeInterface) cachedValuePlan(t reflect.Type) *valuePlan {
+	n, ok := namingCacheKey(dynamic.Naming)
+	if !ok || dynamic.SetType == EmptySet {
+		return nil
+	}
+	fields := make([]string, 0, len(dynamic.FieldSet))
+	for f := range dynamic.FieldSet {
+		fields = append(fields, f)
+	}
+	excluded := make([]string, 0, len(dynamic.Excluded))
+	for f := range dynamic.Excluded {
+		excluded = append(excluded, f)
+	}
+	sort.Strings(fields)
+	sort.Strings(excluded)
+	key := valuePlanKey{fieldNamesKey: fieldNamesKey{t: t, fields: strings.Join(fields, ","), naming: n},
+		excluded: strings.Join(excluded, ",")}
+	if dynamic.SetType == AllSet {
+		key.fields = "*"
+	}
+	if vp, ok := valuePlanCache.Load(key); ok {
+		return vp.(*valuePlan).usable()
+	}
+	plan := &valuePlan{steps: make([]valueStep, 0)}
+	plan.generic = !dynamic.buildValuePlan(plan, t, nil, "")
+	vp, _ := valuePlanCache.LoadOrStore(key, plan)
+	return vp.(*valuePlan).usable()
+}
+
+// usable plan or nil if the generic value generation is needed
+func (vp *valuePlan) usable() *valuePlan {
+	if vp.generic {
+		return nil
+	}
+	return vp
+}
+
+// buildValuePlan add steps of all fields of the structure type to the plan,
+// the same fields in the same order as generateField are used. False is
+// returned if a field needs the generic value generation.
+func (dynamic *typeInterface) buildValuePlan(plan *valuePlan, t reflect.Type, path []int, prefix string) bool {
+	for fi, fm := range structMetadata(t) {
+		if fm.lazy || fm.tagInfo == IgnoreTag {
+			continue
+		}
+		if fm.hasConverter {
+			return false
+		}
+		switch fm.tagInfo {
+		case NormalTag, KeyTag, IndexTag:
+		default:
+			return false
+		}
+		fieldName := fm.columnName(dynamic.Naming, prefix)
+		ft := fm.field.Type
+		index := append(slices.Clone(path), fi)
+		kind := plainValue
+		switch {
+		case IsArrayType(ft):
+			return false
+		case isNullField(ft):
+			kind = valuerValue
+			if ft.Kind() == reflect.Pointer {
+				kind = pointerValue
+			}
+		case ft.Kind() == reflect.Pointer:
+			return false
+		case ft == timeType:
+			kind = addressValue
+		case ft.Kind() == reflect.Struct:
+			if !dynamic.buildValuePlan(plan, ft, index, prefix+StructPrefix(fm.field, dynamic.Naming)) {
+				return false
+			}
+			continue
+		default:
+			switch ft.Kind() {
+			case reflect.Chan, reflect.Func, reflect.Map, reflect.UnsafePointer,
+				reflect.Interface, reflect.Slice:
+				kind = nilableValue
+			default:
+			}
+		}
+		if dynamic.checkFieldSet(fieldName) {
+			plan.steps = append(plan.steps, valueStep{index: index, kind: kind})
+		}
+	}
+	return true
+}
+
+// values extract the values of the structure value using the plan
+func (vp *valuePlan) values(v reflect.Value) ([]any, error) {
+	values := make([]any, 0, len(vp.steps))
+	for _, s := range vp.steps {
+		cv := v.FieldByIndex(s.index)
+		switch s.kind {
+		case nilableValue, pointerValue:
+			if cv.IsNil() {
+				values = append(values, nil)
+				continue
+			}
+			if s.kind == pointerValue {
+				cv = cv.Elem()
+			}
+			values = append(values, cv.Interface())
+		case addressValue:
+			if cv.CanAddr() {
+				values = append(values, cv.Addr().Interface())
+			} else {
+				values = append(values, cv.Interface())
+			}
+		case valuerValue:
+			value := cv.Interface()
+			if valuer, ok := value.(driver.Valuer); ok {
+				dv, err := valuer.Value()
+				if err != nil {
+					return nil, err
+				}
+				value = dv
+			}
+			values = append(values, value)
+		default:
+			values = append(values, cv.Interface())
+		}
+	}
+	return values, nil
+}
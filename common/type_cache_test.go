@@ -0,0 +1,144 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cacheRecord struct {
+	ID      int           `flynn:"id:key"`
+	Name    string        `flynn:"name::40"`
+	Ignored string        `flynn:":ignore"`
+	Address prefixAddress `flynn:"addr:prefix=addr_"`
+}
+
+func TestTypeCacheMetadata(t *testing.T) {
+	InitLog(t)
+
+	rt := reflect.TypeOf(cacheRecord{})
+	meta := structMetadata(rt)
+	if !assert.Len(t, meta, 4) {
+		return
+	}
+	assert.Equal(t, "id", meta[0].tagName)
+	assert.Equal(t, KeyTag, meta[0].tagInfo)
+	assert.Equal(t, IgnoreTag, meta[2].tagInfo)
+	assert.Equal(t, "addr_", StructPrefix(meta[3].field, nil))
+	assert.Equal(t, "ID", meta[0].field.Name)
+	again := structMetadata(rt)
+	assert.Same(t, meta[0], again[0])
+}
+
+func TestTypeCacheFieldNames(t *testing.T) {
+	InitLog(t)
+
+	rt := reflect.TypeOf(cacheRecord{})
+	fn, _, _ := cachedFieldNames(rt, []string{"*"}, nil)
+	assert.Nil(t, fn)
+
+	v := &cacheRecord{ID: 1, Name: "abc", Address: prefixAddress{City: "x"}}
+	ti := CreateInterface(v, []string{"*"})
+	expected := []string{"id", "name", "addr_street", "addr_city"}
+	assert.Equal(t, expected, ti.RowFields)
+	assert.Equal(t, []string{"id"}, ti.RowNames["#key"])
+	ti.RowFields[0] = "changed"
+
+	fn, _, _ = cachedFieldNames(rt, []string{"*"}, nil)
+	if !assert.NotNil(t, fn) {
+		return
+	}
+	assert.Equal(t, expected, fn.rowFields)
+	// field names are taken out of the cache, the walk is not repeated
+	fn.rowFields[1] = "cached"
+	ti = CreateInterface(v, []string{"*"})
+	assert.Equal(t, []string{"id", "cached", "addr_street", "addr_city"}, ti.RowFields)
+	fn.rowFields[1] = "name"
+	ti = CreateInterface(v, []string{"*"})
+	assert.Equal(t, expected, ti.RowFields)
+	values, err := ti.CreateValues(v)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{1, "abc", "", "x"}, values)
+
+	ti = CreateInterfaceNaming(v, []string{"*"}, strings.ToUpper)
+	assert.Equal(t, expected, ti.RowFields)
+	_, _, cacheable := cachedFieldNames(rt, []string{"*"}, strings.ToUpper)
+	assert.False(t, cacheable)
+}
+
+func TestTypeCacheConcurrent(t *testing.T) {
+	InitLog(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v := &cacheRecord{ID: i, Name: "abc"}
+			ti := CreateInterfaceNaming(v, []string{"name", "id"}, SnakeCaseNaming)
+			values, err := ti.CreateValues(v)
+			assert.NoError(t, err)
+			assert.Equal(t, []any{i, "abc"}, values)
+			vd, err := ti.CreateQueryValues()
+			assert.NoError(t, err)
+			assert.Len(t, vd.ScanValues, 2)
+		}(i)
+	}
+	wg.Wait()
+}
+
+type planRecord struct {
+	ID      int64   `flynn:"id:key"`
+	Name    *string `flynn:"name"`
+	Note    sql.NullString
+	Data    []byte
+	Created time.Time
+	Ignored string `flynn:":ignore"`
+	prefixBase
+	Home prefixAddress `flynn:"home"`
+}
+
+func TestTypeCacheValuePlan(t *testing.T) {
+	InitLog(t)
+
+	name := "abc"
+	v := &planRecord{ID: 1, Name: &name, Note: sql.NullString{String: "n", Valid: true},
+		Created: time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC), Home: prefixAddress{City: "x"}}
+	ti := CreateInterface(v, []string{"*"})
+	rt := reflect.TypeOf(planRecord{})
+	plan := ti.cachedValuePlan(rt)
+	if !assert.NotNil(t, plan) {
+		return
+	}
+	assert.Same(t, plan, CreateInterface(v, []string{"*"}).cachedValuePlan(rt))
+	values, err := ti.CreateValues(v)
+	assert.NoError(t, err)
+	ti.resetValues()
+	assert.NoError(t, ti.generateField(reflect.ValueOf(v).Elem(), false, ""))
+	assert.Equal(t, ti.ValueRefTo, values)
+	assert.Equal(t, []any{int64(1), "abc", "n", nil, &v.Created, 0, "", "x"}, values)
+
+	ti = CreateInterface(v, []string{"name", "home_city"})
+	values, err = ti.CreateValues(v)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"abc", "x"}, values)
+
+	ti = CreateInterface(&prefixRecord{}, []string{"*"})
+	assert.Nil(t, ti.cachedValuePlan(reflect.TypeOf(prefixRecord{})))
+}
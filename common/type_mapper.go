@@ -0,0 +1,109 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TypeMapper dialect specific mapping of data types and Go kinds to native
+// SQL types. Each SQL driver provides its own mapper used to create tables.
+type TypeMapper interface {
+	DataType(dataType DataType, length, digits int) string
+	Kind(kind reflect.Kind, length int) string
+	Array(elementType string) string
+}
+
+// DialectTypes table based type mapper. Type formats may contain '%d' verbs
+// which are filled with length and digits. Alpha types longer than
+// MaxAlpha are mapped to the Text type. Dialects without native Arrays
+// store slices JSON encoded in Text columns.
+type DialectTypes struct {
+	Types    map[DataType]string
+	Kinds    map[reflect.Kind]string
+	MaxAlpha int
+	Arrays   bool
+}
+
+// DataType native SQL type of the data type. Integer types wider than
+// four bytes are mapped to the Number type.
+func (dialect *DialectTypes) DataType(dataType DataType, length, digits int) string {
+	switch {
+	case (dataType == Alpha || dataType == Unicode) && dialect.MaxAlpha > 0 &&
+		length > dialect.MaxAlpha:
+		dataType = Text
+	case dataType == Integer && length > 4:
+		dataType = Number
+	}
+	format, ok := dialect.Types[dataType]
+	if !ok {
+		format = sqlTypes[dataType]
+	}
+	return formatType(format, length, digits)
+}
+
+// Kind native SQL type of the Go kind. String kinds are mapped to the
+// Alpha data type.
+func (dialect *DialectTypes) Kind(kind reflect.Kind, length int) string {
+	if kind == reflect.String {
+		return dialect.DataType(Alpha, length, 0)
+	}
+	if format, ok := dialect.Kinds[kind]; ok {
+		return formatType(format, length, 0)
+	}
+	return genericKinds[kind]
+}
+
+// Array native SQL array type of the element type or the Text type used
+// for JSON encoded slices
+func (dialect *DialectTypes) Array(elementType string) string {
+	if dialect.Arrays {
+		return elementType + "[]"
+	}
+	return dialect.DataType(Text, 0, 0)
+}
+
+// formatType fill '%d' verbs of the type format with length and digits
+func formatType(format string, length, digits int) string {
+	switch strings.Count(format, "%d") {
+	case 0:
+		return format
+	case 1:
+		return fmt.Sprintf(format, length)
+	default:
+		return fmt.Sprintf(format, length, digits)
+	}
+}
+
+// genericKinds generic SQL types of Go kinds used if no dialect is known
+var genericKinds = map[reflect.Kind]string{
+	reflect.Int: "INTEGER", reflect.Int8: "INTEGER", reflect.Int16: "INTEGER",
+	reflect.Int32: "INTEGER", reflect.Int64: "INTEGER",
+	reflect.Uint: "INTEGER", reflect.Uint8: "INTEGER", reflect.Uint16: "INTEGER",
+	reflect.Uint32: "INTEGER", reflect.Uint64: "INTEGER",
+	reflect.Float32: "DECIMAL(10,5)", reflect.Float64: "DECIMAL(10,5)",
+	reflect.Bool: "BOOL",
+}
+
+// GenericTypeMapper type mapper of the generic SQL types, byte array types
+// are mapped to 'BYTEA' if byte arrays are available
+func GenericTypeMapper(byteArrayAvailable bool) TypeMapper {
+	dialect := &DialectTypes{Types: map[DataType]string{CurrentTimestamp: "TIMESTAMP"},
+		Kinds: genericKinds}
+	if byteArrayAvailable {
+		dialect.Types[Bytes] = "BYTEA"
+		dialect.Types[BLOB] = "BYTEA"
+	}
+	return dialect
+}
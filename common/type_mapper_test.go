@@ -0,0 +1,47 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypeMapperGeneric(t *testing.T) {
+	InitLog(t)
+	mapper := GenericTypeMapper(false)
+	assert.Equal(t, "VARCHAR(20)", mapper.DataType(Alpha, 20, 0))
+	assert.Equal(t, "DECIMAL(10,5)", mapper.DataType(Decimal, 10, 5))
+	assert.Equal(t, "BINARY(8)", mapper.DataType(Bytes, 8, 0))
+	assert.Equal(t, "BLOB(2048)", mapper.DataType(BLOB, 2048, 0))
+	assert.Equal(t, "TIMESTAMP", mapper.DataType(CurrentTimestamp, 0, 0))
+	assert.Equal(t, "INTEGER", mapper.Kind(reflect.Uint64, 0))
+	assert.Equal(t, "VARCHAR(255)", mapper.Kind(reflect.String, 255))
+	assert.Equal(t, "BOOL", mapper.Kind(reflect.Bool, 0))
+	mapper = GenericTypeMapper(true)
+	assert.Equal(t, "BYTEA", mapper.DataType(Bytes, 8, 0))
+	assert.Equal(t, "BYTEA", mapper.DataType(BLOB, 2048, 0))
+}
+
+func TestTypeMapperDialect(t *testing.T) {
+	InitLog(t)
+	mapper := &DialectTypes{Types: map[DataType]string{Alpha: "VARCHAR2(%d)", Text: "CLOB"},
+		Kinds: map[reflect.Kind]string{reflect.Int64: "NUMBER(19)"}, MaxAlpha: 4000}
+	assert.Equal(t, "VARCHAR2(4000)", mapper.DataType(Alpha, 4000, 0))
+	assert.Equal(t, "CLOB", mapper.DataType(Alpha, 4001, 0))
+	assert.Equal(t, "VARCHAR2(10)", mapper.Kind(reflect.String, 10))
+	assert.Equal(t, "NUMBER(19)", mapper.Kind(reflect.Int64, 0))
+	assert.Equal(t, "INTEGER", mapper.Kind(reflect.Int32, 0))
+	assert.Equal(t, "DATE", mapper.DataType(Date, 0, 0))
+}
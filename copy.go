@@ -0,0 +1,439 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package flynn
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tknie/errorrepo"
+	"github.com/tknie/flynn/common"
+	"github.com/tknie/log"
+)
+
+// DefaultCopyBatchSize default number of records inserted in one bulk insert
+const DefaultCopyBatchSize = 1000
+
+// CopyTransform transform the record of destination column names before it
+// is inserted. The record is skipped if false is returned.
+type CopyTransform func(record map[string]any) (bool, error)
+
+// CopyOptions options of copying a table
+type CopyOptions struct {
+	// Fields source columns copied, all columns are copied if empty
+	Fields []string
+	// Mapping destination column names of source columns, columns mapped
+	// to an empty name are not copied
+	Mapping map[string]string
+	// Transform called for each record before it is inserted
+	Transform CopyTransform
+	// Search restricts the source records copied
+	Search string
+	// BatchSize number of records inserted in one bulk insert
+	BatchSize int
+	// KeyField source key column, records are copied in key order
+	KeyField string
+	// Resume continue an interrupted copy after the highest key found
+	// in the destination table, needs the key field
+	Resume bool
+	// Verify compare row count and checksum of the source records copied
+	// with the records added to the destination
+	Verify bool
+}
+
+// CopyResult result of copying a table
+type CopyResult struct {
+	Copied              int64
+	Skipped             int64
+	Watermark           any
+	SourceCount         int64
+	DestinationCount    int64
+	SourceChecksum      string
+	DestinationChecksum string
+}
+
+// tableCopy copy of source columns into destination columns
+type tableCopy struct {
+	src       common.RegDbID
+	srcTable  string
+	dstTable  string
+	opts      *CopyOptions
+	srcFields []string
+	dstFields []string
+	columns   []*common.Column
+	keyIndex  int
+}
+
+// CopyTable copy the records of the source table into the destination table.
+// The destination table is created with the destination dialect types of the
+// source columns or adapted adding missing columns. Records are read and
+// inserted in batches, each batch is inserted in one transaction. If a key
+// field is given, records are copied in key order and an interrupted copy can
+// be resumed after the highest key copied. The transform callback must not
+// change the key value.
+func CopyTable(src common.RegDbID, srcTable string, dst common.RegDbID, dstTable string, opts *CopyOptions) (*CopyResult, error) {
+	if opts == nil {
+		opts = &CopyOptions{}
+	}
+	tc, err := newTableCopy(src, srcTable, dstTable, opts)
+	if err != nil {
+		return nil, err
+	}
+	err = tc.prepareDestination(dst)
+	if err != nil {
+		return nil, err
+	}
+	result := &CopyResult{}
+	search := opts.Search
+	if opts.Resume && opts.KeyField != "" {
+		result.Watermark, err = tc.watermark(dst)
+		if err != nil {
+			return nil, err
+		}
+		if result.Watermark != nil {
+			condition, err := watermarkCondition(opts.KeyField, result.Watermark)
+			if err != nil {
+				return nil, err
+			}
+			search = tc.joinSearch(search, condition)
+		}
+	}
+	log.Log.Debugf("Copy %s to %s search=%s", srcTable, dstTable, search)
+	var existing *rowChecksum
+	if opts.Verify {
+		existing, err = tc.destinationChecksum(dst)
+		if err != nil {
+			return nil, err
+		}
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultCopyBatchSize
+	}
+	batch := make([][]any, 0, batchSize)
+	var lastKey any
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		_, err := dst.Insert(dstTable, &common.Entries{Fields: tc.dstFields, Values: batch})
+		if err != nil {
+			return err
+		}
+		result.Copied += int64(len(batch))
+		if tc.keyIndex >= 0 {
+			result.Watermark = lastKey
+		}
+		log.Log.Debugf("Copied %d records to %s", result.Copied, dstTable)
+		batch = make([][]any, 0, batchSize)
+		return nil
+	}
+	err = tc.read(search, func(key any, values []any) error {
+		if values == nil {
+			result.Skipped++
+			return nil
+		}
+		batch = append(batch, values)
+		lastKey = key
+		if len(batch) >= batchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return result, err
+	}
+	if opts.Verify {
+		err = tc.verify(dst, search, existing, result)
+	}
+	return result, err
+}
+
+// newTableCopy evaluate source and destination columns of the copy
+func newTableCopy(src common.RegDbID, srcTable, dstTable string, opts *CopyOptions) (*tableCopy, error) {
+	srcColumns, err := src.TableColumns(srcTable)
+	if err != nil {
+		return nil, err
+	}
+	tc := &tableCopy{src: src, srcTable: srcTable, dstTable: dstTable, opts: opts, keyIndex: -1}
+	selected := srcColumns
+	if len(opts.Fields) > 0 {
+		selected = make([]*common.Column, 0, len(opts.Fields))
+		for _, f := range opts.Fields {
+			c := findColumn(srcColumns, f)
+			if c == nil {
+				return nil, errorrepo.NewError("DB000055", f, srcTable)
+			}
+			selected = append(selected, c)
+		}
+	}
+	for _, c := range selected {
+		name := copyColumnName(c.Name)
+		if m, ok := opts.Mapping[c.Name]; ok {
+			if m == "" {
+				continue
+			}
+			name = m
+		}
+		if opts.KeyField != "" && strings.EqualFold(c.Name, opts.KeyField) {
+			tc.keyIndex = len(tc.srcFields)
+		}
+		column := *c
+		column.Name = name
+		column.SubColumns = nil
+		tc.srcFields = append(tc.srcFields, c.Name)
+		tc.dstFields = append(tc.dstFields, name)
+		tc.columns = append(tc.columns, &column)
+	}
+	if opts.KeyField != "" && tc.keyIndex < 0 {
+		return nil, errorrepo.NewError("DB000055", opts.KeyField, srcTable)
+	}
+	return tc, nil
+}
+
+// findColumn search column by name ignoring case
+func findColumn(columns []*common.Column, name string) *common.Column {
+	for _, c := range columns {
+		if strings.EqualFold(c.Name, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// copyColumnName destination column name of source column, characters not
+// valid in SQL names like Adabas '-' are replaced by '_'
+func copyColumnName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// prepareDestination create destination table or adapt it applying all
+// non-destructive changes
+func (tc *tableCopy) prepareDestination(dst common.RegDbID) error {
+	status, err := dst.CreateTableIfNotExists(tc.dstTable, tc.columns)
+	if err != nil {
+		return err
+	}
+	if status != common.CreateExists {
+		log.Log.Debugf("Destination table %s created", tc.dstTable)
+		return nil
+	}
+	plan, err := dst.PlanAdaptTable(tc.dstTable, tc.columns)
+	if err != nil {
+		return err
+	}
+	log.Log.Debugf("Adapt destination table: %s", plan)
+	return dst.ApplyAdaptPlan(plan, false)
+}
+
+// watermark highest key copied into the destination table
+func (tc *tableCopy) watermark(dst common.RegDbID) (any, error) {
+	var watermark any
+	query := &common.Query{TableName: tc.dstTable,
+		Fields: []string{"MAX(" + tc.dstFields[tc.keyIndex] + ")"}}
+	_, err := dst.Query(query, func(search *common.Query, result *common.Result) error {
+		if len(result.Rows) > 0 {
+			watermark = result.Rows[0]
+		}
+		return nil
+	})
+	log.Log.Debugf("Copy watermark of %s: %v", tc.dstTable, watermark)
+	return watermark, err
+}
+
+// joinSearch add condition to the search
+func (tc *tableCopy) joinSearch(search, condition string) string {
+	switch {
+	case search == "":
+		return condition
+	case tc.src.DriverType() == common.AdabasType:
+		return search + " AND " + condition
+	default:
+		return "(" + search + ") AND " + condition
+	}
+}
+
+// watermarkCondition search condition for records after the watermark
+func watermarkCondition(field string, watermark any) (string, error) {
+	switch v := watermark.(type) {
+	case string:
+		return field + ">'" + strings.ReplaceAll(v, "'", "''") + "'", nil
+	case []byte:
+		return field + ">'" + strings.ReplaceAll(string(v), "'", "''") + "'", nil
+	case time.Time:
+		return field + ">'" + v.Format("2006-01-02 15:04:05.999999") + "'", nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprintf("%s>%v", field, v), nil
+	default:
+		return "", errorrepo.NewError("DB000057", fmt.Sprintf("%T", watermark))
+	}
+}
+
+// read read the source records of the search in key order and call the
+// function with the key and the transformed values. Values are nil if the
+// record is skipped by the transformation.
+func (tc *tableCopy) read(search string, f func(key any, values []any) error) error {
+	query := &common.Query{TableName: tc.srcTable, Fields: tc.srcFields, Search: search}
+	if tc.keyIndex >= 0 {
+		query.Order = []string{tc.srcFields[tc.keyIndex] + ":ASC"}
+	}
+	_, err := tc.src.Query(query, func(search *common.Query, result *common.Result) error {
+		values := make([]any, len(tc.srcFields))
+		copy(values, result.Rows)
+		var key any
+		if tc.keyIndex >= 0 {
+			key = values[tc.keyIndex]
+		}
+		values, err := tc.transform(values)
+		if err != nil {
+			return err
+		}
+		return f(key, values)
+	})
+	return err
+}
+
+// transform call transform callback with record of destination names
+func (tc *tableCopy) transform(values []any) ([]any, error) {
+	if tc.opts.Transform == nil {
+		return values, nil
+	}
+	record := make(map[string]any, len(values))
+	for i, name := range tc.dstFields {
+		record[name] = values[i]
+	}
+	ok, err := tc.opts.Transform(record)
+	if err != nil || !ok {
+		return nil, err
+	}
+	for i, name := range tc.dstFields {
+		values[i] = record[name]
+	}
+	return values, nil
+}
+
+// verify compare row count and checksum of the source records of the search
+// with the records added to the destination table since the existing
+// records were checksummed
+func (tc *tableCopy) verify(dst common.RegDbID, search string, existing *rowChecksum, result *CopyResult) error {
+	srcSum := &rowChecksum{}
+	err := tc.read(search, func(key any, values []any) error {
+		if values != nil {
+			srcSum.add(values)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	dstSum, err := tc.destinationChecksum(dst)
+	if err != nil {
+		return err
+	}
+	dstSum.sub(existing)
+	result.SourceCount, result.SourceChecksum = srcSum.count, srcSum.String()
+	result.DestinationCount, result.DestinationChecksum = dstSum.count, dstSum.String()
+	log.Log.Debugf("Verify copy %d/%s -> %d/%s", result.SourceCount, result.SourceChecksum,
+		result.DestinationCount, result.DestinationChecksum)
+	switch {
+	case result.SourceCount != result.DestinationCount:
+		return errorrepo.NewError("DB000056", tc.srcTable, tc.dstTable,
+			fmt.Sprintf("row count %d differs from %d", result.DestinationCount, result.SourceCount))
+	case result.SourceChecksum != result.DestinationChecksum:
+		return errorrepo.NewError("DB000056", tc.srcTable, tc.dstTable, "checksum differs")
+	}
+	return nil
+}
+
+// destinationChecksum checksum of all records of the destination table
+func (tc *tableCopy) destinationChecksum(dst common.RegDbID) (*rowChecksum, error) {
+	dstSum := &rowChecksum{}
+	_, err := dst.Query(&common.Query{TableName: tc.dstTable, Fields: tc.dstFields},
+		func(search *common.Query, r *common.Result) error {
+			dstSum.add(r.Rows)
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return dstSum, nil
+}
+
+// rowChecksum order independent checksum of records. Each record is hashed
+// with SHA-256 and the hashes are summed up, so records read in different
+// order by source and destination get the same checksum.
+type rowChecksum struct {
+	count int64
+	sum   [4]uint64
+}
+
+// add add record values to the checksum
+func (rc *rowChecksum) add(values []any) {
+	h := sha256.New()
+	for _, v := range values {
+		h.Write([]byte(checksumValue(v)))
+		h.Write([]byte{0x1f})
+	}
+	digest := h.Sum(nil)
+	for i := range rc.sum {
+		rc.sum[i] += binary.BigEndian.Uint64(digest[i*8:])
+	}
+	rc.count++
+}
+
+// sub remove the records of the other checksum
+func (rc *rowChecksum) sub(other *rowChecksum) {
+	for i := range rc.sum {
+		rc.sum[i] -= other.sum[i]
+	}
+	rc.count -= other.count
+}
+
+// String hex representation of the checksum
+func (rc *rowChecksum) String() string {
+	return fmt.Sprintf("%016x%016x%016x%016x", rc.sum[0], rc.sum[1], rc.sum[2], rc.sum[3])
+}
+
+// checksumValue normalized value representation, independent of the types
+// different database drivers return for the same column
+func checksumValue(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return "\x00"
+	case string:
+		return strings.TrimRight(value, " ")
+	case []byte:
+		return strings.TrimRight(string(value), " ")
+	case time.Time:
+		return value.UTC().Format(time.RFC3339Nano)
+	case float32:
+		return strconv.FormatFloat(float64(value), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,251 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package flynn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tknie/flynn/common"
+)
+
+// copyDatabase database driver keeping tables in memory
+type copyDatabase struct {
+	common.Database
+	id         common.RegDbID
+	driverType common.ReferenceType
+	columns    map[string][]*common.Column
+	rows       map[string][][]any
+	searches   []string
+	inserts    int
+	calls      []string
+	// change modifies inserted values like a destination truncating them
+	change func(values []any)
+}
+
+func newCopyDatabase(id common.RegDbID, driverType common.ReferenceType) *copyDatabase {
+	cd := &copyDatabase{id: id, driverType: driverType,
+		columns: make(map[string][]*common.Column), rows: make(map[string][][]any)}
+	common.RegisterDbClient(cd)
+	return cd
+}
+
+func (cd *copyDatabase) unregister() {
+	for i, d := range common.Databases {
+		if d == common.Database(cd) {
+			common.Databases = append(common.Databases[:i], common.Databases[i+1:]...)
+			break
+		}
+	}
+}
+
+func (cd *copyDatabase) ID() common.RegDbID { return cd.id }
+
+func (cd *copyDatabase) Used() {}
+
+func (cd *copyDatabase) DriverType() common.ReferenceType { return cd.driverType }
+
+func (cd *copyDatabase) Maps() ([]string, error) {
+	maps := make([]string, 0)
+	for m := range cd.columns {
+		maps = append(maps, m)
+	}
+	return maps, nil
+}
+
+func (cd *copyDatabase) TableColumns(tableName string) ([]*common.Column, error) {
+	return cd.columns[tableName], nil
+}
+
+func (cd *copyDatabase) CreateTable(name string, columns any) error {
+	cd.columns[name] = columns.([]*common.Column)
+	return nil
+}
+
+func (cd *copyDatabase) PlanAdaptTable(name string, newStruct any) (*common.AdaptPlan, error) {
+	return &common.AdaptPlan{Table: name}, nil
+}
+
+func (cd *copyDatabase) ApplyAdaptPlan(plan *common.AdaptPlan, destructive bool) error {
+	return nil
+}
+
+func (cd *copyDatabase) Insert(name string, insert *common.Entries) ([][]any, error) {
+	cd.inserts++
+	if cd.change != nil {
+		for _, v := range insert.Values {
+			cd.change(v)
+		}
+	}
+	cd.rows[name] = append(cd.rows[name], insert.Values...)
+	return nil, nil
+}
+
+func (cd *copyDatabase) BeginTransaction() error {
+	cd.calls = append(cd.calls, "begin")
+	return nil
+}
+
+func (cd *copyDatabase) Commit() error {
+	cd.calls = append(cd.calls, "commit")
+	return nil
+}
+
+func (cd *copyDatabase) Rollback() error {
+	cd.calls = append(cd.calls, "rollback")
+	return nil
+}
+
+func (cd *copyDatabase) Query(search *common.Query, f common.ResultFunction) (*common.Result, error) {
+	cd.searches = append(cd.searches, search.Search)
+	result := &common.Result{Fields: search.Fields}
+	if strings.HasPrefix(search.Fields[0], "MAX(") {
+		var max any
+		for _, r := range cd.rows[search.TableName] {
+			if max == nil || r[0].(int64) > max.(int64) {
+				max = r[0]
+			}
+		}
+		result.Rows = []any{max}
+		return result, f(search, result)
+	}
+	after := int64(-1)
+	if i := strings.LastIndex(search.Search, "id>"); i >= 0 {
+		after, _ = strconv.ParseInt(search.Search[i+3:], 10, 64)
+	}
+	for _, r := range cd.rows[search.TableName] {
+		if r[0].(int64) <= after {
+			continue
+		}
+		result.Rows = append([]any{}, r[:len(search.Fields)]...)
+		err := f(search, result)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func TestCopyTable(t *testing.T) {
+	InitLog(t)
+
+	src := newCopyDatabase(common.RegDbID(4720), common.AdabasType)
+	defer src.unregister()
+	dst := newCopyDatabase(common.RegDbID(4721), common.PostgresType)
+	defer dst.unregister()
+
+	src.columns["EMPLOYEES"] = []*common.Column{
+		{Name: "id", DataType: common.Integer, Length: 8},
+		{Name: "FIRST-NAME", DataType: common.Alpha, Length: 20, Nullable: true},
+		{Name: "BIRTH", DataType: common.Alpha, Length: 10, Nullable: true},
+	}
+	for i := int64(1); i <= 5; i++ {
+		src.rows["EMPLOYEES"] = append(src.rows["EMPLOYEES"],
+			[]any{i, fmt.Sprintf("name%d   ", i), "1970-01-01"})
+	}
+
+	result, err := CopyTable(src.id, "EMPLOYEES", dst.id, "employees", &CopyOptions{
+		Mapping:   map[string]string{"BIRTH": ""},
+		BatchSize: 2,
+		KeyField:  "id",
+		Transform: func(record map[string]any) (bool, error) {
+			return record["id"].(int64) != 3, nil
+		},
+		Verify: true,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, int64(4), result.Copied)
+	assert.Equal(t, int64(1), result.Skipped)
+	assert.Equal(t, int64(5), result.Watermark)
+	assert.Equal(t, int64(4), result.SourceCount)
+	assert.Equal(t, int64(4), result.DestinationCount)
+	assert.Equal(t, result.SourceChecksum, result.DestinationChecksum)
+	assert.Equal(t, 2, dst.inserts)
+	if assert.Len(t, dst.columns["employees"], 2) {
+		assert.Equal(t, "FIRST_NAME", dst.columns["employees"][1].Name)
+		assert.Equal(t, common.Alpha, dst.columns["employees"][1].DataType)
+	}
+	assert.Equal(t, []any{int64(4), "name4   "}, dst.rows["employees"][2])
+
+	// resume after interruption copies only records after the watermark
+	src.rows["EMPLOYEES"] = append(src.rows["EMPLOYEES"], []any{int64(6), "name6", "1970-01-01"})
+	src.searches = nil
+	result, err = CopyTable(src.id, "EMPLOYEES", dst.id, "employees", &CopyOptions{
+		Mapping:  map[string]string{"BIRTH": ""},
+		KeyField: "id",
+		Search:   "id>0",
+		Resume:   true,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"id>0 AND id>5"}, src.searches)
+	assert.Equal(t, int64(1), result.Copied)
+	assert.Equal(t, int64(6), result.Watermark)
+	assert.Len(t, dst.rows["employees"], 5)
+
+	// verification of a resumed copy checks only the records added, also
+	// if existing destination records differ
+	dst.rows["employees"][0][1] = "changed"
+	src.rows["EMPLOYEES"] = append(src.rows["EMPLOYEES"], []any{int64(7), "name7", "1970-01-01"})
+	result, err = CopyTable(src.id, "EMPLOYEES", dst.id, "employees", &CopyOptions{
+		Fields: []string{"id", "first-name"}, KeyField: "id", Search: "id>0", Resume: true, Verify: true})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, int64(1), result.Copied)
+	assert.Equal(t, int64(1), result.SourceCount)
+	assert.Equal(t, int64(1), result.DestinationCount)
+	assert.Equal(t, result.SourceChecksum, result.DestinationChecksum)
+
+	// verification detects differences of the copied records
+	src.rows["EMPLOYEES"] = append(src.rows["EMPLOYEES"], []any{int64(8), "name8", "1970-01-01"})
+	dst.change = func(values []any) { values[1] = values[1].(string)[:2] }
+	_, err = CopyTable(src.id, "EMPLOYEES", dst.id, "employees", &CopyOptions{
+		Fields: []string{"id", "first-name"}, KeyField: "id", Resume: true, Verify: true})
+	assert.EqualError(t, err, "DB000056: copy of EMPLOYEES to employees not verified, checksum differs")
+
+	_, err = CopyTable(src.id, "EMPLOYEES", dst.id, "employees", &CopyOptions{KeyField: "unknown"})
+	assert.EqualError(t, err, "DB000055: column unknown not found in table EMPLOYEES")
+}
+
+func TestCopyWatermark(t *testing.T) {
+	InitLog(t)
+
+	condition, err := watermarkCondition("id", int32(10))
+	assert.NoError(t, err)
+	assert.Equal(t, "id>10", condition)
+	condition, err = watermarkCondition("name", "O'Neil")
+	assert.NoError(t, err)
+	assert.Equal(t, "name>'O''Neil'", condition)
+	condition, err = watermarkCondition("ts", time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, "ts>'2024-02-03 04:05:06'", condition)
+	_, err = watermarkCondition("x", struct{}{})
+	assert.EqualError(t, err, "DB000057: watermark value of type struct {} not supported")
+
+	assert.Equal(t, "FIRST_NAME", copyColumnName("FIRST-NAME"))
+
+	s1, s2 := &rowChecksum{}, &rowChecksum{}
+	s1.add([]any{int32(1), "abc  ", []byte{1, 2}})
+	s1.add([]any{int32(2), nil, 1.5})
+	s2.add([]any{int64(2), nil, float64(1.5)})
+	s2.add([]any{int64(1), []byte("abc"), []byte{1, 2}})
+	assert.Equal(t, s1.String(), s2.String())
+	assert.Equal(t, int64(2), s2.count)
+}
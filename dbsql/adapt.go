@@ -0,0 +1,464 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package dbsql
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/tknie/errorrepo"
+	"github.com/tknie/flynn/common"
+	"github.com/tknie/log"
+	"golang.org/x/exp/slices"
+)
+
+// tableColumn column definition read out of the database catalog
+type tableColumn struct {
+	name     string
+	dataType string
+	length   int
+	scale    int
+	nullable bool
+}
+
+// sqlTypeInfo parsed SQL type used to compare column types
+type sqlTypeInfo struct {
+	family string
+	length int
+	scale  int
+}
+
+type typeChange byte
+
+const (
+	typeUnchanged typeChange = iota
+	typeLengthen
+	typeWiden
+	typeModify
+)
+
+// typeFamilies map of database type names to a common type family
+var typeFamilies = map[string]string{
+	"VARCHAR": "VARCHAR", "CHARACTER VARYING": "VARCHAR", "VARCHAR2": "VARCHAR",
+	"NVARCHAR": "VARCHAR", "NVARCHAR2": "VARCHAR", "UNICODE": "VARCHAR",
+	"CHAR": "CHAR", "BPCHAR": "CHAR", "CHARACTER": "CHAR", "NCHAR": "CHAR",
+	"TEXT": "TEXT", "CLOB": "TEXT", "NCLOB": "TEXT", "MEDIUMTEXT": "TEXT", "LONGTEXT": "TEXT",
+	"TINYINT": "INTEGER", "SMALLINT": "INTEGER", "INT2": "INTEGER", "MEDIUMINT": "INTEGER",
+	"INT": "INTEGER", "INTEGER": "INTEGER", "INT4": "INTEGER", "SERIAL": "INTEGER",
+	"BIGINT": "INTEGER", "INT8": "INTEGER", "BIGSERIAL": "INTEGER",
+	"DECIMAL": "DECIMAL", "NUMERIC": "DECIMAL", "NUMBER": "DECIMAL",
+	"REAL": "FLOAT", "FLOAT": "FLOAT", "FLOAT4": "FLOAT", "FLOAT8": "FLOAT",
+	"DOUBLE": "FLOAT", "DOUBLE PRECISION": "FLOAT", "BINARY_DOUBLE": "FLOAT", "BINARY_FLOAT": "FLOAT",
+	"BOOL": "BOOL", "BOOLEAN": "BOOL",
+	"TIMESTAMP": "TIMESTAMP", "TIMESTAMP WITHOUT TIME ZONE": "TIMESTAMP", "DATETIME": "TIMESTAMP",
+	"TIMESTAMPTZ": "TIMESTAMPTZ", "TIMESTAMP WITH TIME ZONE": "TIMESTAMPTZ",
+	"DATE":  "DATE",
+	"BYTEA": "BINARY", "BLOB": "BINARY", "MEDIUMBLOB": "BINARY", "LONGBLOB": "BINARY",
+	"BINARY": "BINARY", "VARBINARY": "BINARY", "RAW": "BINARY", "LONG RAW": "BINARY",
+	"ARRAY": "ARRAY",
+}
+
+// integerWidth byte width of integer types used to detect widening
+var integerWidth = map[string]int{
+	"TINYINT": 1, "SMALLINT": 2, "INT2": 2, "MEDIUMINT": 3,
+	"INT": 4, "INTEGER": 4, "INT4": 4, "SERIAL": 4,
+	"BIGINT": 8, "INT8": 8, "BIGSERIAL": 8,
+}
+
+// parseSqlType parse SQL type like 'VARCHAR(255)' or 'DECIMAL(10,5)'
+func parseSqlType(sqlType string, length, scale int) *sqlTypeInfo {
+	name := strings.ToUpper(strings.TrimSpace(sqlType))
+	if b := strings.IndexByte(name, '('); b != -1 {
+		args := name[b+1:]
+		suffix := ""
+		if e := strings.IndexByte(args, ')'); e != -1 {
+			suffix = args[e+1:]
+			args = args[:e]
+		}
+		name = strings.TrimSpace(name[:b] + suffix)
+		a := strings.Split(args, ",")
+		if l, err := strconv.Atoi(strings.TrimSpace(a[0])); err == nil {
+			length = l
+		}
+		if len(a) > 1 {
+			if s, err := strconv.Atoi(strings.TrimSpace(a[1])); err == nil {
+				scale = s
+			}
+		}
+	}
+	if strings.HasSuffix(name, "[]") {
+		// native arrays are reported as ARRAY by the catalog
+		name = "ARRAY"
+	}
+	family, ok := typeFamilies[name]
+	if !ok {
+		name = strings.Fields(name)[0]
+		family, ok = typeFamilies[name]
+		if !ok {
+			family = name
+		}
+	}
+	ti := &sqlTypeInfo{family: family, length: length, scale: scale}
+	switch family {
+	case "INTEGER":
+		ti.length = integerWidth[name]
+	case "DECIMAL":
+		// Oracle stores integer as NUMBER without scale
+		if name == "NUMBER" && scale == 0 {
+			ti.family = "INTEGER"
+			ti.length = 0
+		}
+	case "TEXT", "BINARY", "BOOL", "TIMESTAMP", "TIMESTAMPTZ", "DATE", "FLOAT", "ARRAY":
+		ti.length = 0
+	}
+	return ti
+}
+
+// compareType compare desired structure type with current column type
+func compareType(desired *sqlTypeInfo, current *sqlTypeInfo) typeChange {
+	if desired.family != current.family {
+		if desired.family == "BOOL" && current.family == "INTEGER" &&
+			current.length <= 1 {
+			return typeUnchanged
+		}
+		return typeModify
+	}
+	if desired.length == 0 || current.length == 0 {
+		return typeUnchanged
+	}
+	switch {
+	case desired.length == current.length && desired.scale == current.scale:
+		return typeUnchanged
+	case desired.family == "INTEGER" && desired.length > current.length:
+		// widening integer columns rewrites the whole table
+		return typeWiden
+	case desired.length >= current.length && desired.scale >= current.scale:
+		return typeLengthen
+	}
+	return typeModify
+}
+
+func openSchema(dbsql DBschema) (*sql.DB, error) {
+	layer, url := dbsql.Reference()
+	return sql.Open(layer, url)
+}
+
+// catalogTableName table name in the case the database catalog uses
+func catalogTableName(driverType common.ReferenceType, name string) string {
+	if driverType == common.OracleType {
+		return strings.ToUpper(name)
+	}
+	return strings.ToLower(name)
+}
+
+// readTableColumns read column definitions of the table out of the
+// database catalog
+func readTableColumns(db *sql.DB, driverType common.ReferenceType, name string) ([]*tableColumn, error) {
+	var query string
+	switch driverType {
+	case common.PostgresType:
+		query = `SELECT column_name, udt_name, COALESCE(character_maximum_length, numeric_precision, 0),
+ COALESCE(numeric_scale, 0), is_nullable FROM information_schema.columns
+ WHERE table_name = $1 ORDER BY ordinal_position`
+	case common.OracleType:
+		query = `SELECT column_name, data_type, COALESCE(data_precision, char_length, data_length, 0),
+ COALESCE(data_scale, 0), nullable FROM user_tab_columns
+ WHERE table_name = :1 ORDER BY column_id`
+	default:
+		query = `SELECT column_name, data_type, COALESCE(character_maximum_length, numeric_precision, 0),
+ COALESCE(numeric_scale, 0), is_nullable FROM information_schema.columns
+ WHERE table_schema = DATABASE() AND LOWER(table_name) = ? ORDER BY ordinal_position`
+	}
+	rows, err := db.Query(query, catalogTableName(driverType, name))
+	if err != nil {
+		log.Log.Debugf("Error reading table columns: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+	columns := make([]*tableColumn, 0)
+	for rows.Next() {
+		c := &tableColumn{}
+		nullable := ""
+		var length, scale int64
+		err = rows.Scan(&c.name, &c.dataType, &length, &scale, &nullable)
+		if err != nil {
+			return nil, err
+		}
+		c.length = int(length)
+		c.scale = int(scale)
+		c.nullable = nullable == "YES" || nullable == "Y"
+		columns = append(columns, c)
+	}
+	return columns, rows.Err()
+}
+
+// readTableIndexes read index names defined on the table
+func readTableIndexes(db *sql.DB, driverType common.ReferenceType, name string) ([]string, error) {
+	var query string
+	switch driverType {
+	case common.PostgresType:
+		query = "SELECT indexname FROM pg_indexes WHERE tablename = $1"
+	case common.OracleType:
+		query = "SELECT index_name FROM user_indexes WHERE table_name = :1"
+	default:
+		query = `SELECT DISTINCT index_name FROM information_schema.statistics
+ WHERE table_schema = DATABASE() AND LOWER(table_name) = ?`
+	}
+	rows, err := db.Query(query, catalogTableName(driverType, name))
+	if err != nil {
+		log.Log.Debugf("Error reading table indexes: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+	indexes := make([]string, 0)
+	for rows.Next() {
+		index := ""
+		err = rows.Scan(&index)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, strings.ToLower(index))
+	}
+	return indexes, rows.Err()
+}
+
+// indexName name of index managed by structure index tag
+func indexName(table, column string) string {
+	return strings.ToLower(table + "_" + column + "_idx")
+}
+
+// diffTable compare current table definition with the desired structure
+// columns and generate the plan of needed schema changes
+func diffTable(driverType common.ReferenceType, table string, current []*tableColumn,
+	indexes []string, desired []*columnDefinition) *common.AdaptPlan {
+	plan := &common.AdaptPlan{Table: table,
+		Transactional: driverType == common.PostgresType,
+		Steps:         make([]*common.AdaptStep, 0)}
+	currentColumns := make(map[string]*tableColumn)
+	for _, c := range current {
+		currentColumns[strings.ToLower(c.name)] = c
+	}
+	alterTable := "ALTER TABLE " + table + " "
+	used := make(map[string]bool)
+	desiredIndexes := make(map[string]bool)
+	for _, d := range desired {
+		key := strings.ToLower(d.name)
+		c, ok := currentColumns[key]
+		if !ok && d.rename != "" {
+			if c, ok = currentColumns[strings.ToLower(d.rename)]; ok && !used[strings.ToLower(c.name)] {
+				plan.Steps = append(plan.Steps, &common.AdaptStep{Action: common.RenameColumn,
+					Column:    d.name,
+					Statement: alterTable + "RENAME COLUMN " + d.rename + " TO " + d.name})
+			} else {
+				ok = false
+			}
+		}
+		if d.index {
+			desiredIndexes[indexName(table, d.name)] = true
+		}
+		if !ok {
+			plan.Steps = append(plan.Steps, addColumnSteps(driverType, alterTable, d)...)
+			continue
+		}
+		used[strings.ToLower(c.name)] = true
+		desiredType := parseSqlType(d.sqlType, 0, 0)
+		currentType := parseSqlType(c.dataType, c.length, c.scale)
+		if change := compareType(desiredType, currentType); change != typeUnchanged {
+			log.Log.Debugf("Column %s type changed %#v -> %#v", d.name, currentType, desiredType)
+			plan.Steps = append(plan.Steps, &common.AdaptStep{Action: common.AlterColumnType,
+				Column:      d.name,
+				Statement:   alterColumnType(driverType, alterTable, d),
+				Destructive: change != typeLengthen})
+		}
+		switch {
+		case d.notNull && c.nullable:
+			plan.Steps = append(plan.Steps, &common.AdaptStep{Action: common.AlterColumnNull,
+				Column:      d.name,
+				Statement:   alterColumnNull(driverType, alterTable, d, true),
+				Destructive: true})
+		case d.nullable && !c.nullable:
+			plan.Steps = append(plan.Steps, &common.AdaptStep{Action: common.AlterColumnNull,
+				Column:    d.name,
+				Statement: alterColumnNull(driverType, alterTable, d, false)})
+		}
+	}
+	for _, c := range current {
+		if !used[strings.ToLower(c.name)] {
+			plan.Steps = append(plan.Steps, &common.AdaptStep{Action: common.DropColumn,
+				Column: c.name, Statement: alterTable + "DROP COLUMN " + c.name, Destructive: true})
+		}
+	}
+	for _, d := range desired {
+		index := indexName(table, d.name)
+		if d.index && !slices.Contains(indexes, index) {
+			plan.Steps = append(plan.Steps, &common.AdaptStep{Action: common.CreateIndex,
+				Column: d.name, Statement: "CREATE INDEX " + index + " ON " + table + " (" + d.name + ")"})
+		}
+	}
+	prefix := strings.ToLower(table + "_")
+	for _, index := range indexes {
+		if strings.HasPrefix(index, prefix) && strings.HasSuffix(index, "_idx") && !desiredIndexes[index] {
+			dropIndex := "DROP INDEX " + index
+			if driverType == common.MysqlType {
+				dropIndex += " ON " + table
+			}
+			plan.Steps = append(plan.Steps, &common.AdaptStep{Action: common.DropIndex,
+				Column: strings.TrimSuffix(strings.TrimPrefix(index, prefix), "_idx"), Statement: dropIndex})
+		}
+	}
+	return plan
+}
+
+// addColumnSteps steps adding the column. A NOT NULL column without default
+// fails on tables containing records, so it is added nullable and the
+// NOT NULL constraint is set in a separate destructive step.
+func addColumnSteps(driverType common.ReferenceType, alterTable string, d *columnDefinition) []*common.AdaptStep {
+	upperAdditional := strings.ToUpper(d.additional)
+	if !d.notNull || strings.Contains(upperAdditional, "DEFAULT") {
+		return []*common.AdaptStep{{Action: common.AddColumn,
+			Column: d.name, Statement: alterTable + "ADD " + d.String()}}
+	}
+	additional := d.additional
+	if i := strings.Index(upperAdditional, "NOT NULL"); i != -1 {
+		additional = strings.TrimRight(additional[:i], " ") + additional[i+len("NOT NULL"):]
+	}
+	return []*common.AdaptStep{{Action: common.AddColumn, Column: d.name,
+		Statement: alterTable + "ADD " + d.name + " " + d.sqlType + additional},
+		{Action: common.AlterColumnNull, Column: d.name,
+			Statement:   alterColumnNull(driverType, alterTable, d, true),
+			Destructive: true}}
+}
+
+func alterColumnType(driverType common.ReferenceType, alterTable string, d *columnDefinition) string {
+	switch driverType {
+	case common.PostgresType:
+		return alterTable + "ALTER COLUMN " + d.name + " TYPE " + d.sqlType
+	case common.OracleType:
+		return alterTable + "MODIFY (" + d.name + " " + d.sqlType + ")"
+	default:
+	}
+	if d.notNull {
+		return alterTable + "MODIFY COLUMN " + d.name + " " + d.sqlType + " NOT NULL"
+	}
+	return alterTable + "MODIFY COLUMN " + d.name + " " + d.sqlType
+}
+
+func alterColumnNull(driverType common.ReferenceType, alterTable string, d *columnDefinition, notNull bool) string {
+	switch driverType {
+	case common.PostgresType:
+		if notNull {
+			return alterTable + "ALTER COLUMN " + d.name + " SET NOT NULL"
+		}
+		return alterTable + "ALTER COLUMN " + d.name + " DROP NOT NULL"
+	case common.OracleType:
+		if notNull {
+			return alterTable + "MODIFY (" + d.name + " NOT NULL)"
+		}
+		return alterTable + "MODIFY (" + d.name + " NULL)"
+	default:
+	}
+	if notNull {
+		return alterTable + "MODIFY COLUMN " + d.name + " " + d.sqlType + " NOT NULL"
+	}
+	return alterTable + "MODIFY COLUMN " + d.name + " " + d.sqlType + " NULL"
+}
+
+// PlanAdaptTable generate plan of all schema changes needed to adapt the
+// table to the new structure
+func PlanAdaptTable(dbsql DBschema, name string, newStruct any) (*common.AdaptPlan, error) {
+	log.Log.Debugf("%s: Plan adapt SQL table %s", dbsql.ID(), name)
+	db, err := openSchema(dbsql)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	current, err := readTableColumns(db, dbsql.DriverType(), name)
+	if err != nil {
+		return nil, err
+	}
+	if len(current) == 0 {
+		return nil, errorrepo.NewError("DB000036", name)
+	}
+	indexes, err := readTableIndexes(db, dbsql.DriverType(), name)
+	if err != nil {
+		return nil, err
+	}
+	desired, err := structColumns(dbsql.TypeMapper(), dbsql.ID().NamingStrategy(), newStruct, nil)
+	if err != nil {
+		return nil, err
+	}
+	plan := diffTable(dbsql.DriverType(), name, current, indexes, desired)
+	log.Log.Debugf("Adapt plan: %s", plan)
+	return plan, nil
+}
+
+// ApplyAdaptPlan apply the adapt plan. Destructive steps are only applied
+// if destructive is set, otherwise they are skipped. If the database supports
+// transactional schema changes all steps are done in one transaction.
+func ApplyAdaptPlan(dbsql DBschema, plan *common.AdaptPlan, destructive bool) error {
+	if plan == nil || plan.Table == "" {
+		return errorrepo.NewError("DB000035", "")
+	}
+	if plan.Empty() {
+		log.Log.Debugf("No adapt needed for table %s", plan.Table)
+		return nil
+	}
+	db, err := openSchema(dbsql)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var tx *sql.Tx
+	if plan.Transactional {
+		tx, err = db.Begin()
+		if err != nil {
+			return err
+		}
+	}
+	for _, s := range plan.Steps {
+		if s.Destructive && !destructive {
+			log.Log.Infof("Skip destructive adapt step on %s: %s", plan.Table, s.Statement)
+			continue
+		}
+		log.Log.Debugf("Adapt cmd %s", s.Statement)
+		if tx != nil {
+			_, err = tx.Exec(s.Statement)
+		} else {
+			_, err = db.Exec(s.Statement)
+		}
+		if err != nil {
+			log.Log.Errorf("Error returned by SQL: %v", err)
+			if tx != nil {
+				tx.Rollback()
+			}
+			return err
+		}
+	}
+	if tx != nil {
+		return tx.Commit()
+	}
+	log.Log.Debugf("Table adapted")
+	return nil
+}
+
+// AdaptTable adapt table to new struct applying all non-destructive
+// schema changes
+func AdaptTable(dbsql DBschema, name string, newStruct any) error {
+	plan, err := PlanAdaptTable(dbsql, name, newStruct)
+	if err != nil {
+		return err
+	}
+	return ApplyAdaptPlan(dbsql, plan, false)
+}
@@ -0,0 +1,173 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package dbsql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tknie/flynn/common"
+)
+
+type adaptStruct struct {
+	Id       string `flynn:"::20"`
+	Name     string `flynn:"::100"`
+	Street   string `flynn:"street:rename=address:200"`
+	Counter  int64
+	Birth    string `flynn:"birth:index:10"`
+	Approved bool
+}
+
+func adaptCurrentColumns() []*tableColumn {
+	return []*tableColumn{
+		{name: "id", dataType: "varchar", length: 20, nullable: false},
+		{name: "name", dataType: "varchar", length: 50, nullable: false},
+		{name: "address", dataType: "varchar", length: 200, nullable: false},
+		{name: "counter", dataType: "numeric", length: 10, scale: 5, nullable: false},
+		{name: "birth", dataType: "varchar", length: 10, nullable: false},
+		{name: "approved", dataType: "bool", nullable: false},
+		{name: "oldfield", dataType: "int4", length: 32, nullable: false},
+	}
+}
+
+func TestAdaptParseType(t *testing.T) {
+	InitLog(t)
+	assert.Equal(t, &sqlTypeInfo{family: "VARCHAR", length: 255}, parseSqlType("VARCHAR(255)", 0, 0))
+	assert.Equal(t, &sqlTypeInfo{family: "DECIMAL", length: 10, scale: 5}, parseSqlType("DECIMAL(10,5)", 0, 0))
+	assert.Equal(t, &sqlTypeInfo{family: "INTEGER", length: 4}, parseSqlType("int4", 32, 0))
+	assert.Equal(t, &sqlTypeInfo{family: "INTEGER", length: 8}, parseSqlType("BIGINT", 0, 0))
+	assert.Equal(t, &sqlTypeInfo{family: "TIMESTAMPTZ"}, parseSqlType("TIMESTAMP(6) WITH TIME ZONE", 0, 0))
+	assert.Equal(t, &sqlTypeInfo{family: "INTEGER"}, parseSqlType("NUMBER", 38, 0))
+	assert.Equal(t, &sqlTypeInfo{family: "INTEGER", length: 4}, parseSqlType("SERIAL UNIQUE", 0, 0))
+	assert.Equal(t, &sqlTypeInfo{family: "ARRAY"}, parseSqlType("TIMESTAMPTZ[]", 0, 0))
+	assert.Equal(t, typeUnchanged, compareType(parseSqlType("TEXT[]", 0, 0), parseSqlType("ARRAY", 0, 0)))
+
+	assert.Equal(t, typeUnchanged, compareType(parseSqlType("VARCHAR(20)", 0, 0), parseSqlType("character varying", 20, 0)))
+	assert.Equal(t, typeLengthen, compareType(parseSqlType("VARCHAR(200)", 0, 0), parseSqlType("varchar", 20, 0)))
+	assert.Equal(t, typeModify, compareType(parseSqlType("VARCHAR(10)", 0, 0), parseSqlType("varchar", 20, 0)))
+	assert.Equal(t, typeWiden, compareType(parseSqlType("BIGINT", 0, 0), parseSqlType("int4", 32, 0)))
+	assert.Equal(t, typeModify, compareType(parseSqlType("INTEGER", 0, 0), parseSqlType("varchar", 20, 0)))
+	assert.Equal(t, typeUnchanged, compareType(parseSqlType("BOOL", 0, 0), parseSqlType("tinyint", 3, 0)))
+}
+
+func TestAdaptPlanPostgres(t *testing.T) {
+	InitLog(t)
+	desired, err := structColumns(common.GenericTypeMapper(true), nil, &adaptStruct{}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	plan := diffTable(common.PostgresType, "adapttest", adaptCurrentColumns(),
+		[]string{"adapttest_pkey", "adapttest_counter_idx"}, desired)
+	assert.True(t, plan.Transactional)
+	assert.True(t, plan.Destructive())
+	statements := make([]string, 0)
+	for _, s := range plan.Steps {
+		statements = append(statements, s.Statement)
+	}
+	assert.Equal(t, []string{
+		"ALTER TABLE adapttest ALTER COLUMN Name TYPE VARCHAR(100)",
+		"ALTER TABLE adapttest RENAME COLUMN address TO street",
+		"ALTER TABLE adapttest ALTER COLUMN Counter TYPE INTEGER",
+		"ALTER TABLE adapttest DROP COLUMN oldfield",
+		"CREATE INDEX adapttest_birth_idx ON adapttest (birth)",
+		"DROP INDEX adapttest_counter_idx",
+	}, statements)
+	assert.False(t, plan.Steps[0].Destructive)
+	assert.False(t, plan.Steps[1].Destructive)
+	assert.True(t, plan.Steps[2].Destructive)
+	assert.True(t, plan.Steps[3].Destructive)
+	assert.Equal(t, common.DropColumn, plan.Steps[3].Action)
+	assert.Contains(t, plan.String(), "-- destructive: drop column oldfield\n")
+}
+
+func TestAdaptPlanDialects(t *testing.T) {
+	InitLog(t)
+	desired, err := structColumns(common.GenericTypeMapper(false), nil, &struct {
+		Id    string `flynn:"::20"`
+		Name  string `flynn:"::100"`
+		Note  string `flynn:"::80"`
+		Flag  string `flynn:"flag:NOT NULL:1"`
+		Other string `flynn:"other:NULL:5"`
+	}{}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	current := []*tableColumn{
+		{name: "ID", dataType: "VARCHAR2", length: 20},
+		{name: "NAME", dataType: "VARCHAR2", length: 50},
+		{name: "FLAG", dataType: "VARCHAR2", length: 1, nullable: true},
+		{name: "OTHER", dataType: "VARCHAR2", length: 5, nullable: false},
+	}
+	plan := diffTable(common.OracleType, "adapttest", current, nil, desired)
+	assert.False(t, plan.Transactional)
+	assert.Equal(t, "-- adapt table adapttest (5 steps, transactional=false)\n"+
+		"ALTER TABLE adapttest MODIFY (Name VARCHAR(100));\n"+
+		"ALTER TABLE adapttest ADD Note VARCHAR(80);\n"+
+		"-- destructive: alter null Note\n"+
+		"ALTER TABLE adapttest MODIFY (Note NOT NULL);\n"+
+		"-- destructive: alter null flag\n"+
+		"ALTER TABLE adapttest MODIFY (flag NOT NULL);\n"+
+		"ALTER TABLE adapttest MODIFY (other NULL);\n", plan.String())
+
+	plan = diffTable(common.MysqlType, "adapttest", current, []string{"adapttest_name_idx"}, desired)
+	assert.Equal(t, "ALTER TABLE adapttest MODIFY COLUMN Name VARCHAR(100) NOT NULL", plan.Steps[0].Statement)
+	assert.Equal(t, "ALTER TABLE adapttest ADD Note VARCHAR(80)", plan.Steps[1].Statement)
+	assert.False(t, plan.Steps[1].Destructive)
+	assert.Equal(t, "ALTER TABLE adapttest MODIFY COLUMN Note VARCHAR(80) NOT NULL", plan.Steps[2].Statement)
+	assert.True(t, plan.Steps[2].Destructive)
+	assert.Equal(t, "ALTER TABLE adapttest MODIFY COLUMN flag VARCHAR(1) NOT NULL", plan.Steps[3].Statement)
+	assert.Equal(t, "DROP INDEX adapttest_name_idx ON adapttest", plan.Steps[5].Statement)
+
+	plan = diffTable(common.PostgresType, "adapttest", current, nil, []*columnDefinition{
+		{name: "note", sqlType: "VARCHAR(80)", additional: " NOT NULL DEFAULT ''", notNull: true}})
+	assert.Equal(t, "ALTER TABLE adapttest ADD note VARCHAR(80) NOT NULL DEFAULT ''", plan.Steps[0].Statement)
+	assert.False(t, plan.Steps[0].Destructive)
+
+	assert.Equal(t, "-- no changes needed\n", (&common.AdaptPlan{Table: "adapttest"}).String())
+}
+
+func TestAdaptPlanColumns(t *testing.T) {
+	InitLog(t)
+	desired, err := structColumns(common.GenericTypeMapper(true), nil, []*common.Column{
+		{Name: "id", DataType: common.Alpha, Length: 20},
+		{Name: "name", DataType: common.Alpha, Length: 100, Nullable: true},
+		{Name: "note", DataType: common.Alpha, Length: 80, Nullable: true},
+	}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	current := []*tableColumn{
+		{name: "id", dataType: "varchar", length: 20},
+		{name: "name", dataType: "varchar", length: 50, nullable: true},
+	}
+	plan := diffTable(common.PostgresType, "adapttest", current, nil, desired)
+	assert.False(t, plan.Destructive())
+	assert.Equal(t, "-- adapt table adapttest (2 steps, transactional=true)\n"+
+		"ALTER TABLE adapttest ALTER COLUMN name TYPE VARCHAR(100);\n"+
+		"ALTER TABLE adapttest ADD note VARCHAR(80);\n", plan.String())
+}
+
+func TestAdaptPlanIntegerWiden(t *testing.T) {
+	InitLog(t)
+	// tables created before int was mapped to BIGINT contain INTEGER columns
+	desired := []*columnDefinition{{name: "id", sqlType: "VARCHAR(20)", additional: " NOT NULL", notNull: true},
+		{name: "counter", sqlType: "BIGINT", additional: " NOT NULL", notNull: true}}
+	current := []*tableColumn{
+		{name: "id", dataType: "varchar", length: 20},
+		{name: "counter", dataType: "int4", length: 32},
+	}
+	plan := diffTable(common.PostgresType, "adapttest", current, nil, desired)
+	assert.Equal(t, "-- adapt table adapttest (1 steps, transactional=true)\n"+
+		"-- destructive: alter type counter\n"+
+		"ALTER TABLE adapttest ALTER COLUMN counter TYPE BIGINT;\n", plan.String())
+	assert.True(t, plan.Destructive())
+}
@@ -0,0 +1,129 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package dbsql
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/tknie/errorrepo"
+	"github.com/tknie/flynn/common"
+	"github.com/tknie/log"
+	"golang.org/x/exp/slices"
+)
+
+// TableColumns read column definitions of the table out of the database
+// catalog including nullable and primary key information
+func TableColumns(dbsql DBschema, name string) ([]*common.Column, error) {
+	log.Log.Debugf("%s: Read table columns of %s", dbsql.ID(), name)
+	db, err := openSchema(dbsql)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	current, err := readTableColumns(db, dbsql.DriverType(), name)
+	if err != nil {
+		return nil, err
+	}
+	if len(current) == 0 {
+		return nil, errorrepo.NewError("DB000036", name)
+	}
+	keys, err := readPrimaryKey(db, dbsql.DriverType(), name)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]*common.Column, 0, len(current))
+	for _, c := range current {
+		column := catalogColumn(c)
+		column.Key = slices.Contains(keys, strings.ToLower(c.name))
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// readPrimaryKey read primary key column names of the table
+func readPrimaryKey(db *sql.DB, driverType common.ReferenceType, name string) ([]string, error) {
+	var query string
+	switch driverType {
+	case common.PostgresType:
+		query = `SELECT a.attname FROM pg_index i
+ JOIN pg_class c ON c.oid = i.indrelid
+ JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+ WHERE i.indisprimary AND c.relname = $1`
+	case common.OracleType:
+		query = `SELECT cols.column_name FROM user_constraints cons
+ JOIN user_cons_columns cols ON cons.constraint_name = cols.constraint_name
+ WHERE cons.constraint_type = 'P' AND cons.table_name = :1`
+	default:
+		query = `SELECT column_name FROM information_schema.key_column_usage
+ WHERE table_schema = DATABASE() AND constraint_name = 'PRIMARY' AND LOWER(table_name) = ?`
+	}
+	rows, err := db.Query(query, catalogTableName(driverType, name))
+	if err != nil {
+		log.Log.Debugf("Error reading primary key: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+	keys := make([]string, 0)
+	for rows.Next() {
+		key := ""
+		err = rows.Scan(&key)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, strings.ToLower(key))
+	}
+	return keys, rows.Err()
+}
+
+// catalogColumn convert catalog column definition into common column
+// definition
+func catalogColumn(c *tableColumn) *common.Column {
+	ti := parseSqlType(c.dataType, c.length, c.scale)
+	column := &common.Column{Name: c.name, Nullable: c.nullable}
+	switch ti.family {
+	case "VARCHAR":
+		column.DataType = common.Alpha
+		column.Length = uint16(ti.length)
+	case "CHAR":
+		column.DataType = common.Character
+		column.Length = uint16(ti.length)
+	case "TEXT":
+		column.DataType = common.Text
+	case "INTEGER":
+		column.DataType = common.Integer
+		column.Length = uint16(ti.length)
+		if ti.length == 0 {
+			// Oracle NUMBER without scale
+			column.Length = 8
+		}
+	case "DECIMAL":
+		column.DataType = common.Decimal
+		column.Length = uint16(ti.length)
+		column.Digits = uint8(ti.scale)
+	case "FLOAT":
+		column.DataType = common.Float
+	case "BOOL":
+		column.DataType = common.Boolean
+	case "TIMESTAMP", "TIMESTAMPTZ":
+		column.DataType = common.CurrentTimestamp
+	case "DATE":
+		column.DataType = common.Date
+	case "BINARY":
+		column.DataType = common.Bytes
+	default:
+		log.Log.Debugf("Unknown column type %s mapped to text", c.dataType)
+		column.DataType = common.Text
+	}
+	return column
+}
@@ -26,15 +26,22 @@ import (
 	"golang.org/x/exp/slices"
 )
 
-type DBsql interface {
+// DBschema SQL database reference used to create or adapt tables
+type DBschema interface {
 	ID() common.RegDbID
+	Reference() (string, string)
+	ByteArrayAvailable() bool
+	DriverType() common.ReferenceType
+	TypeMapper() common.TypeMapper
+}
+
+type DBsql interface {
+	DBschema
 	Open() (any, error)
 	StartTransaction() (*sql.Tx, context.Context, error)
 	EndTransaction(bool) error
 	Close()
-	Reference() (string, string)
 	IndexNeeded() bool
-	ByteArrayAvailable() bool
 	IsTransaction() bool
 }
 
@@ -49,9 +56,9 @@ func CreateTable(dbsql DBsql, name string, col any) error {
 	createCmd := `CREATE TABLE ` + name + ` (`
 	switch columns := col.(type) {
 	case []*common.Column:
-		createCmd += CreateTableByColumns(dbsql.ByteArrayAvailable(), columns)
+		createCmd += CreateTableByColumns(dbsql.TypeMapper(), columns)
 	default:
-		c, err := CreateTableByStruct(dbsql.ByteArrayAvailable(), col)
+		c, err := CreateTableByStructNaming(dbsql.TypeMapper(), dbsql.ID().NamingStrategy(), col)
 		if err != nil {
 			log.Log.Errorf("Error parsing structure: %v", err)
 			return err
@@ -71,42 +78,6 @@ func CreateTable(dbsql DBsql, name string, col any) error {
 	return nil
 }
 
-// AdaptTable adapt table to new struct
-func AdaptTable(dbsql DBsql, name string, col any) error {
-
-	log.Log.Debugf("%s: Adapt SQL table", dbsql.ID())
-	layer, url := dbsql.Reference()
-	db, err := sql.Open(layer, url)
-	if err != nil {
-		return err
-	}
-	defer db.Close()
-
-	columnCurrent, err := dbsql.ID().GetTableColumn(name)
-	if err != nil {
-		return err
-	}
-	fmt.Println(columnCurrent)
-	log.Log.Debugf("Got columns: %v", columnCurrent)
-	columStruct, err := SqlDataType(true, col, columnCurrent)
-	if err != nil {
-		return err
-	}
-	fmt.Println(columStruct)
-	for _, f := range strings.Split(columStruct, ",") {
-
-		adaptCmd := `ALTER TABLE ` + name + ` ADD ` + f
-		log.Log.Debugf("Create cmd %s", adaptCmd)
-		_, err = db.Query(adaptCmd)
-		if err != nil {
-			log.Log.Errorf("Error returned by SQL: %v", err)
-			return err
-		}
-	}
-	log.Log.Debugf("Table adapted")
-	return nil
-}
-
 func DeleteTable(dbsql DBsql, name string) error {
 	layer, url := dbsql.Reference()
 	db, err := sql.Open(layer, url)
@@ -124,31 +95,35 @@ func DeleteTable(dbsql DBsql, name string) error {
 	return nil
 }
 
-func CreateTableByColumns(baAvailable bool, columns []*common.Column) string {
+// CreateTableByColumns create column definitions of the column list using
+// the dialect type mapper
+func CreateTableByColumns(mapper common.TypeMapper, columns []*common.Column) string {
 	var buffer bytes.Buffer
 	for i, c := range columns {
 		if i > 0 {
 			buffer.WriteString(", ")
 		}
 		buffer.WriteString(c.Name + " ")
-		switch c.DataType {
-		case common.Alpha, common.Bit:
-			buffer.WriteString(c.DataType.SqlType(c.Length))
-		case common.Decimal:
-			buffer.WriteString(c.DataType.SqlType(c.Length, c.Digits))
-		case common.Bytes:
-			buffer.WriteString(c.DataType.SqlType(baAvailable,
-				c.Length))
-		default:
-			buffer.WriteString(c.DataType.SqlType())
-		}
+		buffer.WriteString(mapper.DataType(c.DataType, int(c.Length), int(c.Digits)))
 	}
 	return buffer.String()
 }
 
-func CreateTableByStruct(baAvailable bool, columns any) (string, error) {
+// CreateTableByStruct create column definitions of the structure using
+// the dialect type mapper
+func CreateTableByStruct(mapper common.TypeMapper, columns any) (string, error) {
+	return CreateTableByStructNaming(mapper, nil, columns)
+}
+
+// CreateTableByStructNaming create column definitions of the structure using
+// the dialect type mapper and the naming strategy for the field names
+func CreateTableByStructNaming(mapper common.TypeMapper, naming common.NamingStrategy, columns any) (string, error) {
 	log.Log.Debugf("Create table by structs")
-	return SqlDataType(baAvailable, columns, nil)
+	columnList, err := structColumns(mapper, naming, columns, nil)
+	if err != nil {
+		return "", err
+	}
+	return joinColumns(columnList), nil
 }
 
 func Batch(dbsql DBsql, batch string) error {
@@ -163,10 +138,13 @@ func Batch(dbsql DBsql, batch string) error {
 	if err != nil {
 		return err
 	}
+	defer rows.Close()
 	for rows.Next() {
-		if rows.Err() != nil {
-			fmt.Println("Batch SQL error:", rows.Err())
-		}
+		// result rows of the batch are not needed
+	}
+	if rows.Err() != nil {
+		log.Log.Debugf("Batch SQL error: %v", rows.Err())
+		return rows.Err()
 	}
 	return nil
 }
@@ -199,7 +177,10 @@ func BatchSelect(dbsql DBsql, batch string) ([][]interface{}, error) {
 		if err != nil {
 			return nil, err
 		}
-		data = common.Unpointer(data)
+		data, err = common.ConvertTypeData(ct, data)
+		if err != nil {
+			return nil, err
+		}
 		result = append(result, data)
 	}
 	return result, nil
@@ -238,14 +219,63 @@ func BatchSelectFct(dbsql DBsql, batch *common.Query, fct common.ResultFunction)
 		if err != nil {
 			return err
 		}
-		result.Data = common.Unpointer(data)
+		result.Data, err = common.ConvertTypeData(ct, data)
+		if err != nil {
+			return err
+		}
 		count++
 		fct(nil, result)
 	}
 	return nil
 }
 
+// columnDefinition definition of one table column generated out of
+// structure field
+type columnDefinition struct {
+	name       string
+	sqlType    string
+	additional string
+	notNull    bool
+	nullable   bool
+	index      bool
+	rename     string
+}
+
+func (cd *columnDefinition) String() string {
+	return cd.name + " " + cd.sqlType + cd.additional
+}
+
+func joinColumns(columns []*columnDefinition) string {
+	var buffer bytes.Buffer
+	for _, c := range columns {
+		if buffer.Len() > 0 {
+			buffer.WriteString(", ")
+		}
+		buffer.WriteString(c.String())
+	}
+	return buffer.String()
+}
+
+// SqlDataType generic SQL column definitions of the structure
 func SqlDataType(baAvailable bool, columns any, ignoreList []string) (string, error) {
+	return SqlDataTypeNaming(baAvailable, columns, ignoreList, nil)
+}
+
+// SqlDataTypeNaming generic SQL column definitions of the structure using
+// the naming strategy for the field names
+func SqlDataTypeNaming(baAvailable bool, columns any, ignoreList []string, naming common.NamingStrategy) (string, error) {
+	columnList, err := structColumns(common.GenericTypeMapper(baAvailable), naming, columns, ignoreList)
+	if err != nil {
+		return "", err
+	}
+	return joinColumns(columnList), nil
+}
+
+// structColumns generate column definitions of all structure fields
+func structColumns(mapper common.TypeMapper, naming common.NamingStrategy, columns any, ignoreList []string) ([]*columnDefinition, error) {
+	if list, ok := columns.([]*common.Column); ok {
+		return columnListDefinitions(mapper, list), nil
+	}
 	x := reflect.TypeOf(columns)
 	if x.Kind() == reflect.Pointer {
 		x = x.Elem()
@@ -255,32 +285,49 @@ func SqlDataType(baAvailable bool, columns any, ignoreList []string) (string, er
 
 	switch x.Kind() {
 	case reflect.Struct:
-		var buffer bytes.Buffer
-		first := false
+		columnList := make([]*columnDefinition, 0)
 		for i := 0; i < x.NumField(); i++ {
 			f := x.Field(i)
-			s, err := sqlDataTypeStructField(baAvailable, f, ignoreList)
+			c, err := sqlDataTypeStructField(mapper, naming, f, ignoreList)
 			if err != nil {
-				return "", err
+				return nil, err
 			}
-			if s != "" {
-				if i > 0 && first {
-					buffer.WriteString(", ")
-				}
-				buffer.WriteString(s)
-				first = true
+			columnList = append(columnList, c...)
+		}
+		log.Log.Debugf("Got for type %s: %s", x.Name(), joinColumns(columnList))
+		names := make(map[string]bool)
+		for _, c := range columnList {
+			name := strings.ToLower(c.name)
+			if names[name] {
+				return nil, errorrepo.NewError("DB000064", c.name, x.Name())
 			}
-
+			names[name] = true
 		}
-		log.Log.Debugf("Got for type %s: %s", x.Name(), buffer.String())
-		return buffer.String(), nil
+		return columnList, nil
 	}
 	log.Log.Debugf("Type error, no struct: %T", columns)
-	return "", errorrepo.NewError("DB000005", "", fmt.Sprintf("%T", columns))
+	return nil, errorrepo.NewError("DB000005", "", fmt.Sprintf("%T", columns))
+}
+
+// columnListDefinitions column definitions of the column list using the
+// dialect type mapper
+func columnListDefinitions(mapper common.TypeMapper, columns []*common.Column) []*columnDefinition {
+	columnList := make([]*columnDefinition, 0, len(columns))
+	for _, c := range columns {
+		cd := &columnDefinition{name: c.Name,
+			sqlType:  mapper.DataType(c.DataType, int(c.Length), int(c.Digits)),
+			nullable: c.Nullable}
+		if !c.Nullable {
+			cd.additional = " NOT NULL"
+			cd.notNull = true
+		}
+		columnList = append(columnList, cd)
+	}
+	return columnList
 }
 
-func sqlDataTypeStructField(baAvailable bool, field reflect.StructField,
-	ignoreList []string) (string, error) {
+func sqlDataTypeStructField(mapper common.TypeMapper, naming common.NamingStrategy, field reflect.StructField,
+	ignoreList []string) ([]*columnDefinition, error) {
 	x := field.Type
 	if x.Kind() == reflect.Pointer {
 		x = x.Elem()
@@ -290,133 +337,203 @@ func sqlDataTypeStructField(baAvailable bool, field reflect.StructField,
 		log.Log.Debugf("Check %v %s %v", ignoreList, field.Name, slices.Contains(ignoreList, strings.ToLower(field.Name)))
 	}
 	// Check ignore list
-	if ignoreList != nil && slices.Contains(ignoreList, strings.ToLower(field.Name)) {
-		return "", nil
+	if ignoreList != nil && (slices.Contains(ignoreList, strings.ToLower(field.Name)) ||
+		slices.Contains(ignoreList, strings.ToLower(naming.ColumnName(field.Name)))) {
+		return nil, nil
+	}
+	if tagValue, ok := field.Tag.Lookup(common.TagName); ok {
+		converter, err := common.FieldConverter(tagValue, field.Name)
+		if err != nil {
+			return nil, err
+		}
+		if converter != nil {
+			return converterColumn(mapper, naming, field, converter), nil
+		}
 	}
 	switch x.Kind() {
 	case reflect.Struct:
 		log.Log.Debugf("Check struct")
-		sfi := evaluateName(field, x)
+		sfi := evaluateName(naming, field, x)
 		if sfi.skip {
-			return "", nil
+			return nil, nil
 		}
 		if x.Name() == "Time" {
-			return sfi.name + " TIMESTAMP " + sfi.additional, nil
+			return []*columnDefinition{sfi.column(mapper.DataType(common.CurrentTimestamp, 0, 0))}, nil
+		}
+		if common.IsLazyLOBType(x) {
+			return []*columnDefinition{lazyLOBColumn(mapper, sfi)}, nil
+		}
+		if nf, ok := nullTypeField(field, x); ok {
+			return sqlDataTypeStructFieldDataType(mapper, naming, nf)
 		}
 		if tagValue, ok := field.Tag.Lookup(common.TagName); ok {
 			log.Log.Debugf("Found tag %s for %s", tagValue, field.Name)
 			tagName, tagInfo := common.TagInfoParse(tagValue)
-			fieldName := field.Name
+			fieldName := naming.ColumnName(field.Name)
 			if tagName != "" {
 				fieldName = tagName
 			}
 			switch tagInfo {
 			case common.SubTag:
 				log.Log.Debugf("Found sub type tag")
-				return fieldName + " " + common.Bytes.SqlType(baAvailable, 255), nil
+				return []*columnDefinition{{name: fieldName,
+					sqlType: mapper.DataType(common.Bytes, 255, 0)}}, nil
 			case common.YAMLTag, common.XMLTag, common.JSONTag:
 				log.Log.Debugf("Found conversion tag %s", tagInfo)
-				return fieldName + " " + common.Alpha.SqlType(255), nil
+				return []*columnDefinition{{name: fieldName,
+					sqlType: mapper.DataType(common.Alpha, 255, 0)}}, nil
 			}
 		}
-		var buffer bytes.Buffer
+		columnList := make([]*columnDefinition, 0)
 		for i := 0; i < x.NumField(); i++ {
-			if i > 0 {
-				buffer.WriteString(", ")
-			}
 			f := x.Field(i)
-			s, err := sqlDataTypeStructFieldDataType(baAvailable, f)
+			c, err := sqlDataTypeStructFieldDataType(mapper, naming, f)
 			if err != nil {
-				return "", err
+				return nil, err
 			}
-			buffer.WriteString(s)
+			columnList = append(columnList, c...)
 		}
-		return buffer.String(), nil
+		return prefixColumns(naming, field, columnList), nil
 	default:
-		return sqlDataTypeStructFieldDataType(baAvailable, field)
+		return sqlDataTypeStructFieldDataType(mapper, naming, field)
 	}
 	// return "", NewError(5, field.Name, x.Kind())
 }
 
-func sqlDataTypeStructFieldDataType(baAvailable bool, sf reflect.StructField) (string, error) {
+// converterColumn column definition of field stored using a converter,
+// converted values are stored as text if the converter defines no data type
+func converterColumn(mapper common.TypeMapper, naming common.NamingStrategy, field reflect.StructField,
+	converter common.Converter) []*columnDefinition {
+	sfi := evaluateName(naming, field, field.Type)
+	if sfi.skip {
+		return nil
+	}
+	dataType := common.Alpha
+	if cdt, ok := converter.(common.ConverterDataType); ok {
+		dataType = cdt.DataType()
+	}
+	length := sfi.length
+	if length == 0 {
+		length = 255
+	}
+	log.Log.Debugf("Converter column %s of data type %d", sfi.name, dataType)
+	return []*columnDefinition{sfi.column(mapper.DataType(dataType, length, 0))}
+}
+
+func sqlDataTypeStructFieldDataType(mapper common.TypeMapper, naming common.NamingStrategy,
+	sf reflect.StructField) ([]*columnDefinition, error) {
 	t := sf.Type
-	sfi := evaluateName(sf, t)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if nf, ok := nullTypeField(sf, t); ok {
+		return sqlDataTypeStructFieldDataType(mapper, naming, nf)
+	}
+	sfi := evaluateName(naming, sf, t)
 	if sfi.skip {
-		return "", nil
+		return nil, nil
 	}
 	if sfi.info != "" {
-		return sfi.info, nil
+		return []*columnDefinition{sfi.infoColumn()}, nil
 	}
 	log.Log.Debugf("dbsql name %s and kind %s (%s) (sfi kind=%s)",
 		sfi.name, t.Kind(), t.Name(), sfi.kind)
+	if common.IsLazyLOBType(t) {
+		return []*columnDefinition{lazyLOBColumn(mapper, sfi)}, nil
+	}
 	if t.PkgPath() == "time" && t.Name() == "Time" {
-		return sfi.name + " TIMESTAMP", nil
+		return []*columnDefinition{sfi.nullColumn(&columnDefinition{name: sfi.name,
+			sqlType: mapper.DataType(common.CurrentTimestamp, 0, 0),
+			index:   sfi.index, rename: sfi.rename})}, nil
 	}
 	switch t.Kind() {
 	case reflect.String:
 		switch sfi.kind {
 		case "BLOB", "ABYTE":
-			if baAvailable {
-				return sfi.name + " " + common.Bytes.SqlType(baAvailable, sfi.length), nil
-			}
-			return sfi.name + " " + common.BLOB.SqlType(sfi.length), nil
+			c := &columnDefinition{name: sfi.name, index: sfi.index, rename: sfi.rename,
+				sqlType: mapper.DataType(common.BLOB, sfi.length, 0)}
+			return []*columnDefinition{sfi.nullColumn(c)}, nil
 		default:
 			if sfi.length == 0 {
 				sfi.length = 255
 			}
-			return sfi.name + " " + common.Alpha.SqlType(sfi.length) + sfi.additional, nil
+			return []*columnDefinition{sfi.column(mapper.Kind(reflect.String, sfi.length))}, nil
 		}
 	case reflect.Int, reflect.Int8, reflect.Int16,
 		reflect.Int32, reflect.Int64:
-		return sfi.name + " " + common.Integer.SqlType() + sfi.additional, nil
+		return []*columnDefinition{sfi.column(mapper.Kind(t.Kind(), 0))}, nil
 	case reflect.Uint, reflect.Uint8, reflect.Uint16,
 		reflect.Uint32, reflect.Uint64:
-		return sfi.name + " " + common.Integer.SqlType() + sfi.additional, nil
+		return []*columnDefinition{sfi.column(mapper.Kind(t.Kind(), 0))}, nil
 	case reflect.Float32, reflect.Float64:
 		if sfi.length == 0 {
-			sfi.length = 10
+			return []*columnDefinition{sfi.column(mapper.Kind(t.Kind(), 0))}, nil
 		}
-		return sfi.name + " " + common.Decimal.SqlType(sfi.length, 5) + sfi.additional, nil
+		return []*columnDefinition{sfi.column(mapper.DataType(common.Decimal, sfi.length, 5))}, nil
 	case reflect.Bool:
-		// if sfi.length == 0 {
-		// 	sfi.length = 1
-		// }
-		// sfi.name + " " + common.Bit.SqlType(sfi.length) + sfi.additional, nil
-		return sfi.name + " BOOL" + sfi.additional, nil
+		return []*columnDefinition{sfi.column(mapper.Kind(reflect.Bool, 0))}, nil
 	case reflect.Complex64, reflect.Complex128:
-		return "", errorrepo.NewError("DB000007")
+		return nil, errorrepo.NewError("DB000007")
 	case reflect.Struct:
-		var buffer bytes.Buffer
+		columnList := make([]*columnDefinition, 0)
 		ty := t
 		for i := 0; i < ty.NumField(); i++ {
-			if i > 0 {
-				buffer.WriteString(", ")
-			}
 			f := ty.Field(i)
 			log.Log.Debugf("Struct Field: " + f.Name)
-			s, err := sqlDataTypeStructFieldDataType(baAvailable, f)
+			c, err := sqlDataTypeStructFieldDataType(mapper, naming, f)
 			if err != nil {
-				return "", err
+				return nil, err
 			}
-
-			buffer.WriteString(s)
+			columnList = append(columnList, c...)
 		}
-		buffer.WriteString(sfi.additional)
-		return buffer.String(), nil
+		return prefixColumns(naming, sf, columnList), nil
 	case reflect.Array:
 		log.Log.Debugf("Arrays %d", t.Len())
 		if t.Elem().Kind() == reflect.Uint8 {
-			return sfi.name + " " + common.Character.SqlType(t.Len()) + sfi.additional, nil
+			return []*columnDefinition{sfi.column(mapper.DataType(common.Character, t.Len(), 0))}, nil
 		}
-		return "", errorrepo.NewError("DB000008", sf.Name)
+		return nil, errorrepo.NewError("DB000008", sf.Name)
 	case reflect.Slice:
-		return evaluateSlice(baAvailable, sf, t)
+		return evaluateSlice(mapper, naming, sf, t)
 	default:
 		//		return SqlDataType(t)
 		// + " CONSTRAINT " + t.Name +
 		// 	" CHECK (" + t.Name + " > 0)"
 	}
-	return "", errorrepo.NewError("DB000006", sf.Name, t.Kind())
+	return nil, errorrepo.NewError("DB000006", sf.Name, t.Kind())
+}
+
+// lazyLOBColumn nullable BLOB column of lazy loaded LOB fields
+func lazyLOBColumn(mapper common.TypeMapper, sfi *structFieldInfo) *columnDefinition {
+	return sfi.nullColumn(&columnDefinition{name: sfi.name, sqlType: mapper.DataType(common.BLOB, sfi.length, 0),
+		index: sfi.index, rename: sfi.rename})
+}
+
+// prefixColumns add prefix of the nested structure field to all flattened
+// columns of the structure
+func prefixColumns(naming common.NamingStrategy, sf reflect.StructField, columns []*columnDefinition) []*columnDefinition {
+	prefix := common.StructPrefix(sf, naming)
+	if prefix == "" {
+		return columns
+	}
+	for _, c := range columns {
+		c.name = prefix + c.name
+		if c.rename != "" {
+			c.rename = prefix + c.rename
+		}
+	}
+	return columns
+}
+
+// nullTypeField field definition of the value of null types like
+// sql.NullString, the value type is used as nullable pointer type
+func nullTypeField(sf reflect.StructField, t reflect.Type) (reflect.StructField, bool) {
+	if t.Kind() != reflect.Struct || t.NumField() == 0 ||
+		!reflect.PointerTo(t).Implements(reflect.TypeOf((*sql.Scanner)(nil)).Elem()) {
+		return sf, false
+	}
+	sf.Type = reflect.PointerTo(t.Field(0).Type)
+	return sf, true
 }
 
 type structFieldInfo struct {
@@ -424,13 +541,43 @@ type structFieldInfo struct {
 	additional string
 	info       string
 	kind       string
+	rename     string
 	length     int
+	index      bool
 	skip       bool
+	nullable   bool
+}
+
+// column create column definition of the given SQL type out of the field info
+func (sfi *structFieldInfo) column(sqlType string) *columnDefinition {
+	return sfi.nullColumn(&columnDefinition{name: sfi.name, sqlType: sqlType,
+		additional: sfi.additional, index: sfi.index, rename: sfi.rename})
+}
+
+// nullColumn evaluate null constraint of the column. Columns of fields not
+// able to represent NULL get NOT NULL if no null constraint is given.
+func (sfi *structFieldInfo) nullColumn(c *columnDefinition) *columnDefinition {
+	upperAdditional := strings.ToUpper(c.additional)
+	c.notNull = strings.Contains(upperAdditional, "NOT NULL") ||
+		strings.Contains(upperAdditional, "PRIMARY KEY")
+	c.nullable = !c.notNull && strings.Contains(upperAdditional, "NULL")
+	if !c.notNull && !c.nullable && !sfi.nullable {
+		c.additional = strings.TrimRight(c.additional, " ") + " NOT NULL"
+		c.notNull = true
+	}
+	return c
+}
+
+// infoColumn create column definition out of the complete info type definition
+func (sfi *structFieldInfo) infoColumn() *columnDefinition {
+	return &columnDefinition{name: sfi.name, sqlType: sfi.info, notNull: true,
+		rename: sfi.rename}
 }
 
 // evaluateName evaluate name of type given (extract tags and info)
-func evaluateName(sf reflect.StructField, tsf reflect.Type) *structFieldInfo {
-	sfi := &structFieldInfo{name: sf.Name, skip: false}
+func evaluateName(naming common.NamingStrategy, sf reflect.StructField, tsf reflect.Type) *structFieldInfo {
+	sfi := &structFieldInfo{name: naming.ColumnName(sf.Name), skip: false,
+		nullable: common.IsNullableType(sf.Type)}
 	log.Log.Debugf("Found name " + sfi.name)
 	if tagName, ok := sf.Tag.Lookup(common.TagName); ok {
 		tagField := strings.Split(tagName, ":")
@@ -442,13 +589,21 @@ func evaluateName(sf reflect.StructField, tsf reflect.Type) *structFieldInfo {
 				sfi.skip = true
 				return sfi
 			}
-			sfi.additional = " " + tagField[1]
-			sfi.kind = tagField[1]
+			if rename, ok := common.TagOption(tagName, "rename"); ok {
+				sfi.rename = rename
+			}
+			if _, ok := common.TagOption(tagName, "index"); ok {
+				sfi.index = true
+			}
+			if !common.IsTagOption(tagField[1]) {
+				sfi.additional = " " + tagField[1]
+				sfi.kind = tagField[1]
+			}
 		}
 		log.Log.Debugf("Overwrite to name " + sfi.name)
 		if len(tagField) > 2 && tagField[2] != "" {
 			if tagField[2] == "SERIAL" {
-				sfi.info = sfi.name + " SERIAL UNIQUE"
+				sfi.info = "SERIAL UNIQUE"
 				return sfi
 			}
 			x, err := strconv.Atoi(tagField[2])
@@ -460,20 +615,37 @@ func evaluateName(sf reflect.StructField, tsf reflect.Type) *structFieldInfo {
 	return sfi
 }
 
-func evaluateSlice(baAvailable bool, sf reflect.StructField, t reflect.Type) (string, error) {
+func evaluateSlice(mapper common.TypeMapper, naming common.NamingStrategy, sf reflect.StructField,
+	t reflect.Type) ([]*columnDefinition, error) {
+	if common.IsArrayType(t) {
+		sfi := evaluateName(naming, sf, t)
+		return []*columnDefinition{sfi.column(mapper.Array(arrayElementType(mapper, t.Elem())))}, nil
+	}
 	tt := t.Elem()
 	if tt.Kind() == reflect.Pointer {
 		tt = t.Elem()
 	}
 	switch tt.Kind() {
 	case reflect.Uint8, reflect.Int8:
-		sfi := evaluateName(sf, t)
+		sfi := evaluateName(naming, sf, t)
 		if sfi.info != "" {
-			return sfi.info, nil
+			return []*columnDefinition{sfi.infoColumn()}, nil
 		}
-		return sfi.name + " " + common.Bytes.SqlType(baAvailable, 8) + sfi.additional, nil
+		return []*columnDefinition{sfi.column(mapper.DataType(common.Bytes, 8, 0))}, nil
 	default:
 		log.Log.Debugf("Slice not supported %s (%s)", tt.Kind(), t.Kind())
 	}
-	return "", errorrepo.NewError("DB000009", t.Elem().Kind(), sf.Name)
+	return nil, errorrepo.NewError("DB000009", t.Elem().Kind(), sf.Name)
+}
+
+// arrayElementType native SQL type of array elements
+func arrayElementType(mapper common.TypeMapper, t reflect.Type) string {
+	switch {
+	case t.PkgPath() == "time" && t.Name() == "Time":
+		return mapper.DataType(common.CurrentTimestamp, 0, 0)
+	case t.Kind() == reflect.String:
+		return mapper.DataType(common.Text, 0, 0)
+	default:
+	}
+	return mapper.Kind(t.Kind(), 0)
 }
@@ -0,0 +1,53 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package dbsql
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/tknie/errorrepo"
+	"github.com/tknie/flynn/common"
+	"github.com/tknie/log"
+)
+
+// RangeStatement statement selecting length bytes of the LOB field
+// starting at the offset
+type RangeStatement func(offset int64, length int32) string
+
+// OpenLOB open LOB reader using an own database connection closed with
+// the reader. The size statement selects the LOB length, the range statement
+// the blocks read.
+func OpenLOB(dbsql DBschema, search *common.Query, sizeCmd string, rangeCmd RangeStatement) (common.LOBReader, error) {
+	layer, url := dbsql.Reference()
+	db, err := sql.Open(layer, url)
+	if err != nil {
+		return nil, err
+	}
+	log.Log.Debugf("Open LOB size: %s", sizeCmd)
+	var size sql.NullInt64
+	err = db.QueryRow(sizeCmd).Scan(&size)
+	if err != nil {
+		db.Close()
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errorrepo.NewError("DB000050", search.TableName, search.Search)
+		}
+		return nil, err
+	}
+	return common.NewLOBReader(size.Int64, search.Blocksize, func(offset int64, length int32) ([]byte, error) {
+		cmd := rangeCmd(offset, length)
+		log.Log.Debugf("Read LOB range: %s", cmd)
+		data := make([]byte, 0)
+		err := db.QueryRow(cmd).Scan(&data)
+		return data, err
+	}, db.Close), nil
+}
@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/exp/slices"
 
@@ -45,17 +46,21 @@ func Insert(dbsql DBsql, name string, insert *common.Entries) ([][]any, error) {
 	var insertValues [][]any
 	var insertFields []string
 	if insert.DataStruct != nil {
-		dynamic := common.CreateInterface(insert.DataStruct, insert.Fields)
+		dynamic := common.CreateInterfaceNaming(insert.DataStruct, insert.Fields, insert.Naming)
 		insertFields = dynamic.RowFields
 		for _, vi := range insert.Values {
-			v, err := dynamic.CreateValues(vi[0])
+			value, err := dynamic.PrepareInsert(vi[0])
+			if err != nil {
+				return nil, err
+			}
+			v, err := dynamic.CreateValues(value)
 			if err != nil {
 				return nil, err
 			}
 			insertValues = append(insertValues, v)
 		}
 	} else {
-		insertFields = insert.Fields
+		insertFields = insert.Naming.ColumnNames(insert.Fields)
 		insertValues = insert.Values
 	}
 	log.Log.Debugf("Row   fields: %#v", insertFields)
@@ -117,11 +122,12 @@ func GenerateUpdate(indexNeeded bool, name string, updateInfo *common.Entries) (
 	indexNeed := indexNeeded
 	var insertFields []string
 	if updateInfo.DataStruct != nil {
-		dynamic := common.CreateInterface(updateInfo.DataStruct, updateInfo.Fields)
+		dynamic := common.CreateUpdateInterface(updateInfo)
 		insertFields = dynamic.RowFields
 	} else {
-		insertFields = updateInfo.Fields
+		insertFields = updateInfo.Naming.ColumnNames(updateInfo.Fields)
 	}
+	updateFields := updateInfo.Naming.ColumnNames(updateInfo.Update)
 
 	for i, field := range insertFields {
 		if i > 0 {
@@ -132,7 +138,7 @@ func GenerateUpdate(indexNeeded bool, name string, updateInfo *common.Entries) (
 		} else {
 			insertCmd += "`" + strings.ToLower(field) + "`" + "=?"
 		}
-		if slices.Contains(updateInfo.Update, field) {
+		if slices.Contains(updateInfo.Update, field) || slices.Contains(updateFields, field) {
 			whereFields = append(whereFields, i)
 		}
 	}
@@ -141,30 +147,53 @@ func GenerateUpdate(indexNeeded bool, name string, updateInfo *common.Entries) (
 }
 
 func GenerateDelete(indexNeeded bool, name string, valueIndex int, deleteInfo *common.Entries) (string, []any) {
-	deleteCmd := "DELETE FROM " + name + " WHERE "
+	where, values := deleteWhere(indexNeeded, 0, valueIndex, deleteInfo)
+	return "DELETE FROM " + name + " WHERE " + where, values
+}
+
+// GenerateSoftDelete generate update setting the soft delete timestamp
+// column of all records selected by the delete entries or criteria
+func GenerateSoftDelete(indexNeeded bool, name string, valueIndex int, deleteInfo *common.Entries, column string) (string, []any) {
+	column = strings.ToLower(column)
+	where := deleteInfo.Criteria
+	values := []any{time.Now()}
+	if where == "" {
+		var whereValues []any
+		where, whereValues = deleteWhere(indexNeeded, 1, valueIndex, deleteInfo)
+		values = append(values, whereValues...)
+	}
+	placeholder := "?"
+	if indexNeeded {
+		placeholder = "$1"
+	}
+	return "UPDATE " + name + " SET " + column + "=" + placeholder + " WHERE (" + where + ") AND " +
+		column + " IS NULL", values
+}
 
+// deleteWhere where clause selecting the records of the delete entries
+// value row, offset is the number of parameters used before
+func deleteWhere(indexNeeded bool, offset, valueIndex int, deleteInfo *common.Entries) (string, []any) {
+	var buffer bytes.Buffer
 	values := make([]any, 0)
 	for i, field := range deleteInfo.Fields {
 		if i > 0 {
-			deleteCmd += " AND "
+			buffer.WriteString(" AND ")
 		}
 		if field[0] == '%' {
-			deleteCmd += "(" + field[1:] + " LIKE '" + deleteInfo.Values[0][i].(string) + "')"
+			buffer.WriteString("(" + deleteInfo.Naming.ColumnName(field[1:]) + " LIKE '" +
+				deleteInfo.Values[valueIndex][i].(string) + "')")
 			continue
 		}
-		//deleteCmd += "`" + strings.ToLower(field) + "` IN ("
-		deleteCmd += strings.ToLower(field) + " IN ("
-		//for j := 0; j < len(deleteInfo.Values[0]); j++ {
+		buffer.WriteString(strings.ToLower(deleteInfo.Naming.ColumnName(field)) + " IN (")
 		if indexNeeded {
-			deleteCmd += "$" + strconv.Itoa(i+1)
+			buffer.WriteString("$" + strconv.Itoa(offset+len(values)+1))
 		} else {
-			deleteCmd += "?"
+			buffer.WriteString("?")
 		}
-		values = append(values, deleteInfo.Values[0][i])
-		//}
-		deleteCmd += ")"
+		values = append(values, deleteInfo.Values[valueIndex][i])
+		buffer.WriteString(")")
 	}
-	return deleteCmd, values
+	return buffer.String(), values
 }
 
 func Update(dbsql DBsql, name string, updateInfo *common.Entries) (running [][]any, rowsAffected int64, err error) {
@@ -181,15 +210,21 @@ func Update(dbsql DBsql, name string, updateInfo *common.Entries) (running [][]a
 	log.Log.Debugf("CMD: %s - %s", insertCmd, whereFields)
 	var insertFields []string
 	var insertValues [][]any
+	var versions []*common.UpdateVersion
 	if updateInfo.DataStruct != nil {
-		dynamic := common.CreateInterface(updateInfo.DataStruct, updateInfo.Fields)
+		dynamic := common.CreateUpdateInterface(updateInfo)
 		insertFields = dynamic.RowFields
 		for _, vi := range updateInfo.Values {
-			v, err := dynamic.CreateValues(vi[0])
+			value, version, err := dynamic.PrepareUpdate(vi[0])
+			if err != nil {
+				return nil, -1, err
+			}
+			v, err := dynamic.CreateValues(value)
 			if err != nil {
 				return nil, -1, err
 			}
 			insertValues = append(insertValues, v)
+			versions = append(versions, version)
 			log.Log.Debugf("Row   fields: %#v", insertFields)
 			log.Log.Debugf("Value fields: %#v", insertValues)
 		}
@@ -197,17 +232,37 @@ func Update(dbsql DBsql, name string, updateInfo *common.Entries) (running [][]a
 		insertValues = updateInfo.Values
 	}
 	for i, v := range insertValues {
-		whereClause := CreateWhere(i, updateInfo, whereFields)
+		var whereClause string
+		if updateInfo.DataStruct != nil {
+			whereClause = CreateWhereValues(updateInfo, insertFields, v, whereFields)
+		} else {
+			whereClause = CreateWhere(i, updateInfo, whereFields)
+		}
+		var version *common.UpdateVersion
+		if i < len(versions) {
+			version = versions[i]
+		}
+		if version != nil {
+			whereClause = VersionWhere(dbsql.IndexNeeded(), whereClause, version, len(v)+1)
+			v = append(v, version.Old)
+		}
 		ic := insertCmd + whereClause
 		log.Log.Debugf("Update CMD: %s", ic)
 		log.Log.Debugf("Update values: %d -> %#v", len(v), v)
 		res, err := tx.ExecContext(ctx, ic, v...)
 		if err != nil {
 			log.Log.Debugf("Update error: %s -> %v", ic, err)
+			common.ResetVersions(versions)
 			dbsql.EndTransaction(false)
 			return nil, 0, err
 		}
 		ra, _ := res.RowsAffected()
+		if version != nil && ra == 0 {
+			log.Log.Debugf("Update version conflict: %s", ic)
+			common.ResetVersions(versions)
+			dbsql.EndTransaction(false)
+			return nil, 0, common.VersionConflict(name, version.Old)
+		}
 		rowsAffected += ra
 	}
 	log.Log.Debugf("Update done")
@@ -230,7 +285,31 @@ func Update(dbsql DBsql, name string, updateInfo *common.Entries) (running [][]a
 	return nil, rowsAffected, nil
 }
 
+// VersionWhere add optimistic locking condition of the version field to
+// the where clause, the old version is the parameter at the given index
+func VersionWhere(indexNeeded bool, whereClause string, version *common.UpdateVersion, index int) string {
+	condition := "`" + strings.ToLower(version.Field) + "`=?"
+	if indexNeeded {
+		condition = `"` + strings.ToLower(version.Field) + `"=$` + strconv.Itoa(index)
+	}
+	if whereClause == "" {
+		return condition
+	}
+	return whereClause + " AND " + condition
+}
+
 func CreateWhere(valueIndex int, updateInfo *common.Entries, whereFields []int) string {
+	var values []any
+	if valueIndex < len(updateInfo.Values) {
+		values = updateInfo.Values[valueIndex]
+	}
+	return CreateWhereValues(updateInfo, updateInfo.Fields, values, whereFields)
+}
+
+// CreateWhereValues create where clause of the update conditions and the
+// where fields out of the given field list and value row. Used for structure
+// updates where the values are created out of the structure.
+func CreateWhereValues(updateInfo *common.Entries, fields []string, values []any, whereFields []int) string {
 	var buffer bytes.Buffer
 	for i, x := range updateInfo.Update {
 		if strings.ContainsAny(x, "=<>") {
@@ -244,9 +323,9 @@ func CreateWhere(valueIndex int, updateInfo *common.Entries, whereFields []int)
 		if buffer.Len() > 0 || i > 0 {
 			buffer.WriteString(" AND ")
 		}
-		buffer.WriteString(`"` + strings.ToLower(updateInfo.Fields[s]) + `"`)
+		buffer.WriteString(`"` + strings.ToLower(updateInfo.Naming.ColumnName(fields[s])) + `"`)
 		buffer.WriteRune('=')
-		buffer.WriteString(convertString(updateInfo.Values[valueIndex][s]))
+		buffer.WriteString(convertString(values[s]))
 	}
 	return buffer.String()
 }
@@ -269,11 +348,16 @@ func Delete(dbsql DBsql, name string, updateInfo *common.Entries) (rowsAffected
 		defer dbsql.Close()
 	}
 
+	softDelete := updateInfo.SoftDeleteColumn()
 	if updateInfo.Criteria != "" {
 		deleteCmd := "DELETE FROM " + name + " WHERE " + updateInfo.Criteria
+		var av []any
+		if softDelete != "" {
+			deleteCmd, av = GenerateSoftDelete(dbsql.IndexNeeded(), name, 0, updateInfo, softDelete)
+		}
 
 		log.Log.Debugf("Delete cmd: %s", deleteCmd)
-		res, err := tx.ExecContext(ctx, deleteCmd)
+		res, err := tx.ExecContext(ctx, deleteCmd, av...)
 		if err != nil {
 			log.Log.Debugf("Delete error: %v", err)
 			dbsql.EndTransaction(false)
@@ -284,7 +368,10 @@ func Delete(dbsql DBsql, name string, updateInfo *common.Entries) (rowsAffected
 		rowsAffected += ra
 	} else {
 		for i := 0; i < len(updateInfo.Values); i++ {
-			deleteCmd, av := GenerateDelete(dbsql.IndexNeeded(), name, 0, updateInfo)
+			deleteCmd, av := GenerateDelete(dbsql.IndexNeeded(), name, i, updateInfo)
+			if softDelete != "" {
+				deleteCmd, av = GenerateSoftDelete(dbsql.IndexNeeded(), name, i, updateInfo, softDelete)
+			}
 			log.Log.Debugf("Delete cmd: %s -> %#v", deleteCmd, av)
 			res, err := tx.ExecContext(ctx, deleteCmd, av...)
 			if err != nil {
@@ -303,3 +390,28 @@ func Delete(dbsql DBsql, name string, updateInfo *common.Entries) (rowsAffected
 	log.Log.Debugf("Delete done")
 	return
 }
+
+// Execute execute SQL statement with parameters. If a transaction is
+// started the statement is part of the transaction.
+func Execute(dbsql DBsql, statement string, args ...any) (rowsAffected int64, err error) {
+	tx, ctx, err := dbsql.StartTransaction()
+	if err != nil {
+		return -1, err
+	}
+	if !dbsql.IsTransaction() {
+		defer dbsql.Close()
+	}
+	log.Log.Debugf("Execute cmd: %s -> %#v", statement, args)
+	res, err := tx.ExecContext(ctx, statement, args...)
+	if err != nil {
+		log.Log.Debugf("Execute error: %v", err)
+		dbsql.EndTransaction(false)
+		return -1, err
+	}
+	rowsAffected, _ = res.RowsAffected()
+	err = dbsql.EndTransaction(true)
+	if err != nil {
+		return -1, err
+	}
+	return
+}
@@ -144,3 +144,135 @@ func TestSQLDelete(t *testing.T) {
 	assert.Equal(t, "DELETE FROM TABLENAME WHERE abc IN (?) AND bcd IN (?) AND (YYY LIKE 'XXX%')", sqlCmd)
 	assert.Equal(t, []interface{}{"abc", 123}, rows)
 }
+
+func TestSQLNaming(t *testing.T) {
+	InitLog(t)
+	log.Log.Debugf("TEST: %s", t.Name())
+
+	type namingRecord struct {
+		UserID    int
+		FirstName string
+		CreatedAt time.Time
+		Nick      string `flynn:"NickName"`
+	}
+	ui := &common.Entries{
+		DataStruct: &namingRecord{},
+		Fields:     []string{"*"},
+		Update:     []string{"UserID"},
+		Naming:     common.SnakeCaseNaming,
+	}
+	sqlCmd, rows := GenerateUpdate(true, "users", ui)
+	assert.Equal(t, "UPDATE users SET \"user_id\"=$1,\"first_name\"=$2,\"created_at\"=$3,\"nickname\"=$4 WHERE ", sqlCmd)
+	assert.Equal(t, []int{0}, rows)
+
+	ui = &common.Entries{
+		Fields: []string{"UserID", "%FirstName"},
+		Values: [][]any{{1, "A%"}},
+		Naming: common.SnakeCaseNaming,
+	}
+	sqlCmd, _ = GenerateDelete(false, "users", 0, ui)
+	assert.Equal(t, "DELETE FROM users WHERE user_id IN (?) AND (first_name LIKE 'A%')", sqlCmd)
+
+	s, err := SqlDataTypeNaming(false, &namingRecord{}, []string{"created_at"}, common.SnakeCaseNaming)
+	assert.NoError(t, err)
+	assert.Equal(t, "user_id INTEGER NOT NULL, first_name VARCHAR(255) NOT NULL, NickName VARCHAR(255) NOT NULL", s)
+}
+
+func TestSQLUpdateVersion(t *testing.T) {
+	InitLog(t)
+	log.Log.Debugf("TEST: %s", t.Name())
+
+	type versionRecord struct {
+		ID       int
+		Created  time.Time `flynn:"created:autocreate"`
+		Modified time.Time `flynn:"modified:autoupdate"`
+		Version  int       `flynn:"version:version"`
+	}
+	ui := &common.Entries{
+		DataStruct: &versionRecord{},
+		Fields:     []string{"*"},
+		Update:     []string{"ID=1"},
+	}
+	sqlCmd, _ := GenerateUpdate(true, "records", ui)
+	assert.Equal(t, "UPDATE records SET \"id\"=$1,\"modified\"=$2,\"version\"=$3 WHERE ", sqlCmd)
+	version := &common.UpdateVersion{Field: "version", Old: 2}
+	assert.Equal(t, "ID=1 AND \"version\"=$4", VersionWhere(true, CreateWhere(0, ui, nil), version, 4))
+	assert.Equal(t, "`version`=?", VersionWhere(false, "", version, 4))
+
+	s, err := SqlDataType(false, &versionRecord{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "ID INTEGER NOT NULL, created TIMESTAMP NOT NULL, modified TIMESTAMP NOT NULL, version INTEGER NOT NULL", s)
+}
+
+func TestSQLSoftDelete(t *testing.T) {
+	InitLog(t)
+	log.Log.Debugf("TEST: %s", t.Name())
+
+	type softRecord struct {
+		Name    string     `flynn:"name"`
+		Deleted *time.Time `flynn:"deleted_at:softdelete"`
+	}
+	ui := &common.Entries{
+		DataStruct: &softRecord{},
+		Fields:     []string{"name"},
+		Values:     [][]any{{"abc"}, {"xyz"}},
+	}
+	assert.Equal(t, "deleted_at", ui.SoftDeleteColumn())
+	sqlCmd, values := GenerateSoftDelete(true, "records", 1, ui, ui.SoftDeleteColumn())
+	assert.Equal(t, "UPDATE records SET deleted_at=$1 WHERE (name IN ($2)) AND deleted_at IS NULL", sqlCmd)
+	if assert.Len(t, values, 2) {
+		assert.IsType(t, time.Time{}, values[0])
+		assert.Equal(t, "xyz", values[1])
+	}
+	ui.Criteria = "name LIKE 'a%'"
+	sqlCmd, values = GenerateSoftDelete(false, "records", 0, ui, "deleted_at")
+	assert.Equal(t, "UPDATE records SET deleted_at=? WHERE (name LIKE 'a%') AND deleted_at IS NULL", sqlCmd)
+	assert.Len(t, values, 1)
+
+	ui.HardDelete = true
+	assert.Equal(t, "", ui.SoftDeleteColumn())
+	sqlCmd, values = GenerateDelete(true, "records", 1, ui)
+	assert.Equal(t, "DELETE FROM records WHERE name IN ($1)", sqlCmd)
+	assert.Equal(t, []any{"xyz"}, values)
+}
+
+func TestSQLUpdateChanged(t *testing.T) {
+	InitLog(t)
+	log.Log.Debugf("TEST: %s", t.Name())
+
+	type changedRecord struct {
+		ID   int    `flynn:"id"`
+		Name string `flynn:"name"`
+		Data []byte `flynn:"data"`
+	}
+	v := &changedRecord{ID: 3, Name: "abc", Data: []byte{1}}
+	snapshot, err := common.TakeSnapshot(v)
+	if !assert.NoError(t, err) {
+		return
+	}
+	v.Name = "xyz"
+	changed, err := snapshot.Changed(v)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name"}, changed)
+
+	ui := &common.Entries{
+		DataStruct: v,
+		Fields:     append(changed, "id"),
+		Update:     []string{"id"},
+		Values:     [][]any{{v}},
+	}
+	sqlCmd, whereFields := GenerateUpdate(true, "records", ui)
+	assert.Equal(t, "UPDATE records SET \"id\"=$1,\"name\"=$2 WHERE ", sqlCmd)
+	assert.Equal(t, []int{0}, whereFields)
+	dynamic := common.CreateUpdateInterface(ui)
+	values, err := dynamic.CreateValues(v)
+	assert.NoError(t, err)
+	assert.Equal(t, "\"id\"=3", CreateWhereValues(ui, dynamic.RowFields, values, whereFields))
+}
+
+func TestSQLStreamWriteCount(t *testing.T) {
+	InitLog(t)
+
+	assert.Equal(t, "SELECT COUNT(*) FROM Pictures WHERE checksum='abc'",
+		countStatement(&common.Query{TableName: "Pictures", Fields: []string{"media"}, Search: "checksum='abc'"}))
+}
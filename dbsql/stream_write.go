@@ -0,0 +1,86 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package dbsql
+
+import (
+	"io"
+
+	"github.com/tknie/errorrepo"
+	"github.com/tknie/flynn/common"
+	"github.com/tknie/log"
+)
+
+// BlockStatement statement and arguments writing a block of a chunked LOB
+// write, first is set for the block replacing the field content
+type BlockStatement func(first bool, block []byte) (string, []any)
+
+// StreamWrite write the reader data in blocks into the field of the
+// records matching the search. All blocks are written in one transaction
+// using the statements of the block statement function.
+func StreamWrite(dbsql DBsql, search *common.Query, reader io.Reader, statement BlockStatement) (int64, error) {
+	return streamWrite(dbsql, search, reader, statement, common.StreamBlocks)
+}
+
+// StreamWriteText write the reader text like StreamWrite, blocks end at
+// character boundaries
+func StreamWriteText(dbsql DBsql, search *common.Query, reader io.Reader, statement BlockStatement) (int64, error) {
+	return streamWrite(dbsql, search, reader, statement, common.StreamTextBlocks)
+}
+
+func streamWrite(dbsql DBsql, search *common.Query, reader io.Reader, statement BlockStatement,
+	blocks func(io.Reader, int32, common.BlockFunction) (int64, error)) (int64, error) {
+	transaction := dbsql.IsTransaction()
+	tx, ctx, err := dbsql.StartTransaction()
+	if err != nil {
+		return 0, err
+	}
+	if !transaction {
+		defer dbsql.Close()
+	}
+	// rows affected cannot be used, MySQL does not count rows updated
+	// with an equal value and Oracle PL/SQL blocks report no rows
+	var count int64
+	err = tx.QueryRowContext(ctx, countStatement(search)).Scan(&count)
+	if err == nil && count == 0 {
+		err = errorrepo.NewError("DB000050", search.TableName, search.Search)
+	}
+	n := int64(0)
+	if err == nil {
+		n, err = blocks(reader, search.Blocksize, func(offset int64, block []byte) error {
+			cmd, args := statement(offset == 0, block)
+			if cmd == "" {
+				return nil
+			}
+			log.Log.Debugf("Stream write %d bytes at %d: %s", len(block), offset, cmd)
+			_, err := tx.ExecContext(ctx, cmd, args...)
+			return err
+		})
+	}
+	if err != nil {
+		log.Log.Debugf("Stream write error: %v", err)
+		dbsql.EndTransaction(false)
+		return n, err
+	}
+	if !transaction {
+		err = dbsql.EndTransaction(true)
+		if err != nil {
+			return n, err
+		}
+	}
+	log.Log.Debugf("Stream write finished: %d bytes", n)
+	return n, nil
+}
+
+// countStatement statement counting the records of the search
+func countStatement(search *common.Query) string {
+	return "SELECT COUNT(*) FROM " + search.TableName + " WHERE " + search.Search
+}
@@ -15,6 +15,7 @@ import (
 	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/tknie/flynn/common"
@@ -111,7 +112,7 @@ func TestDataTypeStructBlogs(t *testing.T) {
 
 	s, err := SqlDataType(tSQL.ByteArrayAvailable(), &x, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, "St VARCHAR(255), Int INTEGER", s)
+	assert.Equal(t, "St VARCHAR(255) NOT NULL, Int INTEGER NOT NULL", s)
 	y := struct {
 		XSt   string
 		ZBlob string `flynn:"SBLOB:BLOB:2048"`
@@ -122,23 +123,28 @@ func TestDataTypeStructBlogs(t *testing.T) {
 	}{"aaa", "fjrpsgj", 1, struct{ Xii uint64 }{2}}
 	s, err = SqlDataType(tSQL.ByteArrayAvailable(), &y, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, "XSt VARCHAR(255), SBLOB BYTEA, XInt INTEGER, Xii INTEGER", s)
+	assert.Equal(t, "XSt VARCHAR(255) NOT NULL, SBLOB BYTEA NOT NULL, XInt INTEGER NOT NULL, Xstr_Xii INTEGER NOT NULL", s)
 	global := &GlobStruct{}
 	s, err = SqlDataType(tSQL.ByteArrayAvailable(), global, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, "Test VARCHAR(255), ABC VARCHAR(255), Nr INTEGER, Value INTEGER, Doub DECIMAL(10,5), DoIt BOOL", s)
+	assert.Equal(t, "Test VARCHAR(255) NOT NULL, Sub_ABC VARCHAR(255) NOT NULL, Sub_Nr INTEGER NOT NULL, Sub_Value INTEGER NOT NULL, Sub_Doub DECIMAL(10,5) NOT NULL, Sub_DoIt BOOL NOT NULL", s)
 	global2 := &GlobStruct2{}
 	s, err = SqlDataType(tSQL.ByteArrayAvailable(), global2, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, "Test VARCHAR(255), ABC VARCHAR(255), Nr INTEGER, Value INTEGER, Doub DECIMAL(10,5), DoIt BOOL", s)
+	assert.Equal(t, "Test VARCHAR(255) NOT NULL, Sub_ABC VARCHAR(255) NOT NULL, Sub_Nr INTEGER NOT NULL, Sub_Value INTEGER NOT NULL, Sub_Doub DECIMAL(10,5) NOT NULL, Sub_DoIt BOOL NOT NULL", s)
 	global3 := &GlobStruct3{}
 	s, err = SqlDataType(tSQL.ByteArrayAvailable(), global3, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, "Test VARCHAR(255), XYZ VARCHAR(255), UUU VARCHAR(255), ID INTEGER IDENTITY(1, 1), Value INTEGER, Doub DECIMAL(10,5), DoIt BOOL", s)
+	assert.Equal(t, "Test VARCHAR(255) NOT NULL, Sub_XYZ VARCHAR(255) NOT NULL, Sub_UUU VARCHAR(255) NOT NULL, Sub_ID INTEGER IDENTITY(1, 1) NOT NULL, Sub_Value INTEGER NOT NULL, Sub_Doub DECIMAL(10,5) NOT NULL, Sub_DoIt BOOL NOT NULL", s)
 	slice := &SliceStruct{}
 	s, err = SqlDataType(tSQL.ByteArrayAvailable(), slice, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Test TEXT, Sub_ABC VARCHAR(255) NOT NULL, Sub_Nr INTEGER NOT NULL, Sub_Value INTEGER NOT NULL, Sub_Doub DECIMAL(10,5) NOT NULL, Sub_DoIt BOOL NOT NULL", s)
+	s, err = SqlDataType(tSQL.ByteArrayAvailable(), &struct {
+		Test []SubStruct
+	}{}, nil)
 	assert.Error(t, err)
-	assert.Equal(t, "DB000009: Slice types string are not supported used by field Test", err.Error())
+	assert.Equal(t, "DB000009: Slice types struct are not supported used by field Test", err.Error())
 	assert.Equal(t, "", s)
 	arr := &ArrayStruct{}
 	s, err = SqlDataType(tSQL.ByteArrayAvailable(), arr, nil)
@@ -157,12 +163,12 @@ func TestDataTypeStructBlogs(t *testing.T) {
 	}{"aaa", "djfgidjfgi", []byte{1, 9}, nil, nil, nil, nil}
 	s, err = SqlDataType(tSQL.ByteArrayAvailable(), &z, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, "KKK VARCHAR(1024) , ABC VARCHAR(200) , ZBlob BYTEA, ABC VARCHAR(255), Nr INTEGER, Value INTEGER, Doub DECIMAL(10,5), DoIt BOOL, YYY VARCHAR(255), XXX VARCHAR(255), JJJ VARCHAR(255)", s)
+	assert.Equal(t, "KKK VARCHAR(1024) NOT NULL, ABC VARCHAR(200) NOT NULL, ZBlob BYTEA, NNN_ABC VARCHAR(255) NOT NULL, NNN_Nr INTEGER NOT NULL, NNN_Value INTEGER NOT NULL, NNN_Doub DECIMAL(10,5) NOT NULL, NNN_DoIt BOOL NOT NULL, YYY VARCHAR(255), XXX VARCHAR(255), JJJ VARCHAR(255)", s)
 
 	ti := common.CreateInterface(&z, []string{"*"})
-	assert.Equal(t, []string{"KKK", "ABC", "ZBlob", "ABC", "Nr", "Value", "Doub", "DoIt", "YYY", "XXX", "JJJ"}, ti.RowFields)
+	assert.Equal(t, []string{"KKK", "ABC", "ZBlob", "NNN_ABC", "NNN_Nr", "NNN_Value", "NNN_Doub", "NNN_DoIt", "YYY", "XXX", "JJJ"}, ti.RowFields)
 	ti = common.CreateInterface(&z, []string{"*"})
-	assert.Equal(t, []string{"KKK", "ABC", "ZBlob", "ABC", "Nr", "Value", "Doub", "DoIt", "YYY", "XXX", "JJJ"}, ti.RowFields)
+	assert.Equal(t, []string{"KKK", "ABC", "ZBlob", "NNN_ABC", "NNN_Nr", "NNN_Value", "NNN_Doub", "NNN_DoIt", "YYY", "XXX", "JJJ"}, ti.RowFields)
 
 	ti = common.CreateInterface(&GlobStruct{}, []string{"*"})
 	v, err := ti.CreateValues(&GlobStruct{Test: "ABCBCC"})
@@ -185,6 +191,102 @@ func TestDataTypeStructTag(t *testing.T) {
 
 	s, err := SqlDataType(tSQL.ByteArrayAvailable(), &zz, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, "St VARCHAR(255), AA VARCHAR(6) , Int INTEGER, Ba BYTEA, Ca CHAR(4)", s)
+	assert.Equal(t, "St VARCHAR(255) NOT NULL, AA VARCHAR(6) NOT NULL, Int INTEGER NOT NULL, Ba BYTEA, Ca CHAR(4) NOT NULL", s)
+
+}
+
+type convPoint struct {
+	X, Y int
+}
+
+func TestDataTypeStructConverter(t *testing.T) {
+	InitLog(t)
+	log.Log.Debugf("TEST: %s", t.Name())
+
+	zz := struct {
+		St    string
+		Price int64      `flynn:"price:conv=valuer:20"`
+		Point *convPoint `flynn:"point:conv=gob"`
+		Tags  []string   `flynn:"tags:conv=unknown"`
+	}{}
 
+	_, err := SqlDataType(tSQL.ByteArrayAvailable(), &zz, []string{"tags"})
+	assert.NoError(t, err)
+	s, err := SqlDataType(false, &zz, []string{"tags"})
+	assert.NoError(t, err)
+	assert.Equal(t, "St VARCHAR(255) NOT NULL, price VARCHAR(20) NOT NULL, point BLOB(255)", s)
+	_, err = SqlDataType(false, &zz, nil)
+	assert.Error(t, err)
+}
+
+func TestDataTypeStructNullable(t *testing.T) {
+	InitLog(t)
+	log.Log.Debugf("TEST: %s", t.Name())
+
+	zz := struct {
+		Name    string
+		Note    *string
+		Counter *int64
+		Created *time.Time
+		Changed time.Time
+		Title   sql.NullString `flynn:"title::40"`
+		Amount  sql.NullInt64
+		Flag    string `flynn:"flag:NULL:1"`
+	}{}
+
+	s, err := SqlDataType(tSQL.ByteArrayAvailable(), &zz, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Name VARCHAR(255) NOT NULL, Note VARCHAR(255), Counter INTEGER, Created TIMESTAMP, "+
+		"Changed TIMESTAMP NOT NULL, title VARCHAR(40) , Amount INTEGER, flag VARCHAR(1) NULL", s)
+}
+
+type prefixAddress struct {
+	Street string `flynn:"street::40"`
+	City   string `flynn:"city::40"`
+}
+
+type prefixBase struct {
+	ID      int64 `flynn:"id"`
+	Created time.Time
+}
+
+func TestDataTypeStructPrefix(t *testing.T) {
+	InitLog(t)
+	log.Log.Debugf("TEST: %s", t.Name())
+
+	zz := struct {
+		prefixBase
+		Home prefixAddress  `flynn:"home:prefix=home_"`
+		Work *prefixAddress `flynn:"work:prefix=work_"`
+		Plan prefixAddress
+	}{}
+
+	s, err := SqlDataType(tSQL.ByteArrayAvailable(), &zz, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "id INTEGER NOT NULL, Created TIMESTAMP NOT NULL, home_street VARCHAR(40) NOT NULL, "+
+		"home_city VARCHAR(40) NOT NULL, work_street VARCHAR(40) NOT NULL, work_city VARCHAR(40) NOT NULL, "+
+		"Plan_street VARCHAR(40) NOT NULL, Plan_city VARCHAR(40) NOT NULL", s)
+
+	_, err = SqlDataType(tSQL.ByteArrayAvailable(), &struct {
+		Home prefixAddress `flynn:"home:prefix="`
+		Work prefixAddress `flynn:"work:prefix="`
+	}{}, nil)
+	assert.EqualError(t, err, "DB000064: column street defined more than once in structure ")
+}
+
+func TestDataTypeStructLazyLOB(t *testing.T) {
+	InitLog(t)
+	log.Log.Debugf("TEST: %s", t.Name())
+
+	zz := struct {
+		Checksum string          `flynn:"checksum::40"`
+		Media    common.LazyLOB  `flynn:"media"`
+		Thumb    *common.LazyLOB `flynn:"thumbnail"`
+		Address  prefixAddress   `flynn:"address:prefix=address_"`
+	}{}
+
+	s, err := SqlDataType(tSQL.ByteArrayAvailable(), &zz, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "checksum VARCHAR(40) NOT NULL, media BYTEA, thumbnail BYTEA, "+
+		"address_street VARCHAR(40) NOT NULL, address_city VARCHAR(40) NOT NULL", s)
 }
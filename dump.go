@@ -0,0 +1,211 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package flynn
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/tknie/errorrepo"
+	"github.com/tknie/flynn/common"
+	"github.com/tknie/log"
+)
+
+const (
+	// dumpVersion version of the dump archive format
+	dumpVersion = 1
+	// dumpSchemaFile name of the schema entry of the dump archive
+	dumpSchemaFile = "schema.json"
+)
+
+// DumpSchema schema entry of the dump archive describing the dumped tables
+type DumpSchema struct {
+	Version int          `json:"version"`
+	Created time.Time    `json:"created"`
+	Driver  string       `json:"driver"`
+	Tables  []*DumpTable `json:"tables"`
+}
+
+// DumpTable table of the dump archive with the columns and the name of the
+// gzip compressed JSON Lines data entry
+type DumpTable struct {
+	Name    string           `json:"name"`
+	File    string           `json:"file"`
+	Columns []*common.Column `json:"columns"`
+}
+
+// Dump write the tables in the database independent dump format to the
+// writer, all tables are dumped if no table is given. The dump is a tar
+// archive containing the schema entry followed by one gzip compressed JSON
+// Lines data entry per table.
+func Dump(id common.RegDbID, tables []string, w io.Writer) error {
+	if len(tables) == 0 {
+		var err error
+		tables, err = id.Tables()
+		if err != nil {
+			return err
+		}
+	}
+	schema := &DumpSchema{Version: dumpVersion, Created: time.Now().UTC(),
+		Driver: id.DriverType().String(), Tables: make([]*DumpTable, 0, len(tables))}
+	for _, table := range tables {
+		columns, err := id.TableColumns(table)
+		if err != nil {
+			return err
+		}
+		schema.Tables = append(schema.Tables, &DumpTable{Name: table,
+			File: "data/" + table + ".ndjson.gz", Columns: columns})
+	}
+	tw := tar.NewWriter(w)
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = tw.WriteHeader(&tar.Header{Name: dumpSchemaFile, Mode: 0644,
+		Size: int64(len(data)), ModTime: schema.Created})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	if err != nil {
+		return err
+	}
+	for _, table := range schema.Tables {
+		err = dumpTable(id, table, tw, schema.Created)
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// dumpTable write the data entry of the table. The records are compressed
+// into a temporary file first because the entry size is needed in advance.
+func dumpTable(id common.RegDbID, table *DumpTable, tw *tar.Writer, created time.Time) error {
+	tmp, err := os.CreateTemp("", "flynn-dump-*.gz")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	gz := gzip.NewWriter(tmp)
+	exporter, err := common.NewExporter(common.JSONLinesExport, gz, nil)
+	if err != nil {
+		return err
+	}
+	fields := make([]string, 0, len(table.Columns))
+	for _, c := range table.Columns {
+		fields = append(fields, c.Name)
+	}
+	_, err = id.Query(&common.Query{TableName: table.Name, Fields: fields}, exporter.Write)
+	if err != nil {
+		return err
+	}
+	err = exporter.Close()
+	if err != nil {
+		return err
+	}
+	err = gz.Close()
+	if err != nil {
+		return err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	log.Log.Debugf("Dump %d records of %s", exporter.Counter, table.Name)
+	err = tw.WriteHeader(&tar.Header{Name: table.File, Mode: 0644, Size: size, ModTime: created})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, tmp)
+	return err
+}
+
+// Restore read the dump archive and restore its tables. Tables are created
+// with the types of the target database, column names not valid in SQL are
+// adapted like in CopyTable. Records are appended to tables already existing.
+// The schema of the dump is returned.
+func Restore(id common.RegDbID, r io.Reader) (*DumpSchema, error) {
+	tr := tar.NewReader(r)
+	header, err := tr.Next()
+	if err != nil {
+		return nil, err
+	}
+	if header.Name != dumpSchemaFile {
+		return nil, errorrepo.NewError("DB000062", "schema missing")
+	}
+	schema := &DumpSchema{}
+	err = json.NewDecoder(tr).Decode(schema)
+	if err != nil {
+		return nil, err
+	}
+	if schema.Version != dumpVersion {
+		return nil, errorrepo.NewError("DB000062", "version not supported")
+	}
+	tables := make(map[string]*DumpTable)
+	for _, table := range schema.Tables {
+		columns := make([]*common.Column, 0, len(table.Columns))
+		for _, c := range table.Columns {
+			column := *c
+			column.Name = copyColumnName(c.Name)
+			columns = append(columns, &column)
+		}
+		_, err = id.CreateTableIfNotExists(table.Name, columns)
+		if err != nil {
+			return nil, err
+		}
+		tables[table.File] = table
+	}
+	for {
+		header, err = tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		table, ok := tables[header.Name]
+		if !ok {
+			return nil, errorrepo.NewError("DB000062", "entry "+header.Name+" unknown")
+		}
+		err = restoreTable(id, table, tr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return schema, nil
+}
+
+// restoreTable insert the records of the data entry into the table
+func restoreTable(id common.RegDbID, table *DumpTable, r io.Reader) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	result, err := Import(id, table.Name, gz, common.JSONLinesExport, nil)
+	if err != nil {
+		return err
+	}
+	log.Log.Debugf("Restored %d records of %s", result.Imported, table.Name)
+	return nil
+}
@@ -0,0 +1,84 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package flynn
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tknie/flynn/common"
+)
+
+func TestDumpRestore(t *testing.T) {
+	InitLog(t)
+
+	src := newCopyDatabase(common.RegDbID(4740), common.PostgresType)
+	defer src.unregister()
+	dst := newCopyDatabase(common.RegDbID(4741), common.OracleType)
+	defer dst.unregister()
+
+	src.columns["pictures"] = []*common.Column{
+		{Name: "id", DataType: common.Integer, Length: 8, Key: true},
+		{Name: "FILE-NAME", DataType: common.Alpha, Length: 255, Nullable: true},
+		{Name: "data", DataType: common.Bytes, Length: 100, Nullable: true},
+		{Name: "taken", DataType: common.CurrentTimestamp, Nullable: true},
+		{Name: "rating", DataType: common.Float, Nullable: true},
+	}
+	taken := time.Date(2024, 2, 3, 4, 5, 6, 789000000, time.UTC)
+	src.rows["pictures"] = [][]any{
+		{int64(1), "a.jpg", []byte{0, 1, 2, 0xff}, taken, 4.5},
+		{int64(2), "", nil, nil, nil},
+	}
+	src.columns["empty"] = []*common.Column{{Name: "id", DataType: common.Integer, Length: 8}}
+
+	var buffer bytes.Buffer
+	err := Dump(src.id, []string{"pictures", "empty"}, &buffer)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tr := tar.NewReader(bytes.NewReader(buffer.Bytes()))
+	names := make([]string, 0)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		names = append(names, header.Name)
+	}
+	assert.Equal(t, []string{"schema.json", "data/pictures.ndjson.gz", "data/empty.ndjson.gz"}, names)
+
+	schema, err := Restore(dst.id, bytes.NewReader(buffer.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Postgres", schema.Driver)
+	assert.Len(t, schema.Tables, 2)
+	if assert.Len(t, dst.columns["pictures"], 5) {
+		assert.Equal(t, "FILE_NAME", dst.columns["pictures"][1].Name)
+		assert.Equal(t, common.Bytes, dst.columns["pictures"][2].DataType)
+		assert.True(t, dst.columns["pictures"][0].Key)
+	}
+	assert.Equal(t, src.rows["pictures"], dst.rows["pictures"])
+	assert.Len(t, dst.columns["empty"], 1)
+	assert.Empty(t, dst.rows["empty"])
+
+	_, err = Restore(dst.id, bytes.NewReader([]byte{}))
+	assert.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,568 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package flynn
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tknie/errorrepo"
+	"github.com/tknie/flynn/common"
+	"github.com/tknie/log"
+)
+
+// DefaultImportSampleSize default number of records used to infer types
+const DefaultImportSampleSize = 100
+
+// DefaultDateFormats default layouts of date and time values
+var DefaultDateFormats = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999", "2006-01-02"}
+
+// ImportOptions options of importing records
+type ImportOptions struct {
+	// Header column names of CSV input without header line
+	Header []string
+	// Mapping column names of input names, input names mapped to an
+	// empty name are not imported
+	Mapping map[string]string
+	// Infer infer column types of a sample and create the table if it
+	// does not exist
+	Infer bool
+	// SampleSize number of records used to infer column types
+	SampleSize int
+	// Types data types of columns overriding inferred or table types
+	Types map[string]common.DataType
+	// DateFormats layouts of date and time values
+	DateFormats []string
+	// NullValues texts read as NULL, default is the empty text
+	NullValues []string
+	// Comma field delimiter of CSV, default is ','
+	Comma rune
+	// MaxErrors number of bad records skipped and reported before the
+	// import fails
+	MaxErrors int
+	// BatchSize number of records inserted in one bulk insert
+	BatchSize int
+	// Transaction insert each batch in a transaction of its own
+	Transaction bool
+}
+
+// ImportError error of a skipped input record
+type ImportError struct {
+	Line int64
+	Err  error
+}
+
+// Error error message of the input record
+func (ie *ImportError) Error() string {
+	return fmt.Sprintf("line %d: %v", ie.Line, ie.Err)
+}
+
+// ImportResult result of an import
+type ImportResult struct {
+	Imported int64
+	Skipped  int64
+	Errors   []*ImportError
+	// Columns columns of the table created with inferred types
+	Columns []*common.Column
+}
+
+// importRecord input record with values of the names, nil values are NULL
+type importRecord struct {
+	line   int64
+	names  []string
+	values []*string
+	err    error
+}
+
+// importSource input of import records, io.EOF is returned at the end
+type importSource interface {
+	next() (*importRecord, error)
+}
+
+// importer import of the input records into a table
+type importer struct {
+	id      common.RegDbID
+	table   string
+	opts    *ImportOptions
+	names   []string
+	fields  []string
+	columns []*common.Column
+	result  *ImportResult
+}
+
+// Import read CSV or JSON Lines records and insert them into the table in
+// batches. With inference the column types are evaluated out of a sample of
+// records and the table is created if it does not exist. Bad records are
+// skipped and reported up to the maximal number of errors.
+func Import(id common.RegDbID, table string, reader io.Reader, format common.ExportFormat, opts *ImportOptions) (*ImportResult, error) {
+	if opts == nil {
+		opts = &ImportOptions{}
+	}
+	var source importSource
+	switch format {
+	case common.CSVExport:
+		source = newCSVSource(reader, opts)
+	case common.JSONLinesExport:
+		source = &jsonSource{reader: bufio.NewReader(reader), opts: opts}
+	default:
+		return nil, errorrepo.NewError("DB000058", format.String())
+	}
+	im := &importer{id: id, table: table, opts: opts, result: &ImportResult{}}
+	sampleSize := opts.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = DefaultImportSampleSize
+	}
+	sample := make([]*importRecord, 0, sampleSize)
+	for len(sample) < sampleSize {
+		record, err := source.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return im.result, err
+		}
+		sample = append(sample, record)
+	}
+	err := im.prepare(sample)
+	if err != nil {
+		return im.result, err
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultCopyBatchSize
+	}
+	batch := make([][]any, 0, batchSize)
+	add := func(record *importRecord) error {
+		values, err := im.convert(record)
+		if err != nil {
+			im.result.Skipped++
+			ie := &ImportError{Line: record.line, Err: err}
+			im.result.Errors = append(im.result.Errors, ie)
+			log.Log.Debugf("Import skip %v", ie)
+			if len(im.result.Errors) > opts.MaxErrors {
+				return errorrepo.NewError("DB000059", table, record.line, err)
+			}
+			return nil
+		}
+		batch = append(batch, values)
+		if len(batch) < batchSize {
+			return nil
+		}
+		err = im.insert(batch)
+		batch = make([][]any, 0, batchSize)
+		return err
+	}
+	for _, record := range sample {
+		err = add(record)
+		if err != nil {
+			return im.result, err
+		}
+	}
+	for {
+		record, err := source.next()
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			err = add(record)
+		}
+		if err != nil {
+			return im.result, err
+		}
+	}
+	if len(batch) > 0 {
+		err = im.insert(batch)
+	}
+	return im.result, err
+}
+
+// prepare evaluate input names and table columns, with inference the table
+// is created with the types of the sample
+func (im *importer) prepare(sample []*importRecord) error {
+	known := make(map[string]bool)
+	for _, record := range sample {
+		for _, name := range record.names {
+			if !known[name] {
+				known[name] = true
+				im.names = append(im.names, name)
+			}
+		}
+	}
+	fieldNames := make([]string, 0, len(im.names))
+	for _, name := range im.names {
+		field := copyColumnName(name)
+		if m, ok := im.opts.Mapping[name]; ok {
+			field = m
+		}
+		fieldNames = append(fieldNames, field)
+	}
+	if im.opts.Infer {
+		inferred := make([]*common.Column, 0, len(im.names))
+		for i, name := range im.names {
+			if fieldNames[i] == "" {
+				continue
+			}
+			c := im.inferColumn(sample, name)
+			c.Name = fieldNames[i]
+			if dataType, ok := im.opts.Types[c.Name]; ok {
+				c.DataType = dataType
+			}
+			inferred = append(inferred, c)
+		}
+		status, err := im.id.CreateTableIfNotExists(im.table, inferred)
+		if err != nil {
+			return err
+		}
+		if status == common.CreateCreated {
+			im.result.Columns = inferred
+		}
+	}
+	tableColumns, err := im.id.TableColumns(im.table)
+	if err != nil {
+		return err
+	}
+	im.fields = make([]string, len(im.names))
+	for i, field := range fieldNames {
+		if field == "" {
+			continue
+		}
+		c := findColumn(tableColumns, field)
+		if c == nil {
+			return errorrepo.NewError("DB000055", field, im.table)
+		}
+		if dataType, ok := im.opts.Types[field]; ok {
+			column := *c
+			column.DataType = dataType
+			c = &column
+		}
+		im.fields[i] = c.Name
+		im.columns = append(im.columns, c)
+	}
+	log.Log.Debugf("Import %v into %s", im.names, im.table)
+	return nil
+}
+
+// inferColumn infer column type of the values of the input name
+func (im *importer) inferColumn(sample []*importRecord, name string) *common.Column {
+	isInt, isFloat, isBool, isDate, isTime := true, true, true, true, true
+	maxLength := 0
+	found := false
+	for _, record := range sample {
+		if record.err != nil {
+			continue
+		}
+		for i, n := range record.names {
+			if n != name || record.values[i] == nil {
+				continue
+			}
+			found = true
+			v := *record.values[i]
+			maxLength = max(maxLength, utf8.RuneCountInString(v))
+			_, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			isInt = isInt && err == nil
+			_, err = strconv.ParseFloat(strings.TrimSpace(v), 64)
+			isFloat = isFloat && err == nil
+			_, err = strconv.ParseBool(v)
+			isBool = isBool && err == nil
+			_, dateOnly, err := im.parseTime(v)
+			isTime = isTime && err == nil
+			isDate = isDate && err == nil && dateOnly
+		}
+	}
+	column := &common.Column{Name: name, Nullable: true}
+	switch {
+	case !found:
+		column.DataType, column.Length = common.Alpha, 255
+	case isInt:
+		column.DataType, column.Length = common.Integer, 8
+	case isFloat:
+		column.DataType = common.Float
+	case isBool:
+		column.DataType = common.Boolean
+	case isDate:
+		column.DataType = common.Date
+	case isTime:
+		column.DataType = common.CurrentTimestamp
+	case maxLength > 255:
+		column.DataType = common.Text
+	default:
+		column.DataType, column.Length = common.Alpha, 255
+	}
+	log.Log.Debugf("Inferred column %s type %d", name, column.DataType)
+	return column
+}
+
+// parseTime parse time using the date formats, dateOnly is set if the
+// format contains no time of day
+func (im *importer) parseTime(v string) (t time.Time, dateOnly bool, err error) {
+	formats := im.opts.DateFormats
+	if len(formats) == 0 {
+		formats = DefaultDateFormats
+	}
+	for _, layout := range formats {
+		t, err = time.Parse(layout, strings.TrimSpace(v))
+		if err == nil {
+			return t, !strings.Contains(layout, "04"), nil
+		}
+	}
+	return
+}
+
+// convert values of the record into the column types
+func (im *importer) convert(record *importRecord) ([]any, error) {
+	if record.err != nil {
+		return nil, record.err
+	}
+	values := make([]any, 0, len(im.columns))
+	for i, field := range im.fields {
+		if field == "" {
+			continue
+		}
+		v := record.value(im.names[i])
+		if v == nil {
+			values = append(values, nil)
+			continue
+		}
+		c := im.columns[len(values)]
+		value, err := im.convertValue(c, *v)
+		if err != nil {
+			return nil, errorrepo.NewError("DB000060", *v, c.Name)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// convertValue convert text into value of the column data type
+func (im *importer) convertValue(c *common.Column, v string) (any, error) {
+	switch c.DataType {
+	case common.Integer, common.Number:
+		return strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	case common.Decimal:
+		return decimalText(v)
+	case common.Float:
+		return strconv.ParseFloat(strings.TrimSpace(v), 64)
+	case common.Boolean, common.Bit:
+		return strconv.ParseBool(strings.TrimSpace(v))
+	case common.Date, common.CurrentTimestamp:
+		t, _, err := im.parseTime(v)
+		return t, err
+	case common.Bytes, common.BLOB:
+		return base64.StdEncoding.DecodeString(v)
+	default:
+		return v, nil
+	}
+}
+
+// decimalText check decimal number text, the text is kept to be inserted
+// without loss of precision
+func decimalText(v string) (string, error) {
+	v = strings.TrimSpace(v)
+	digits := strings.TrimLeft(v, "+-")
+	if len(v)-len(digits) > 1 {
+		return "", strconv.ErrSyntax
+	}
+	integer, fraction, _ := strings.Cut(digits, ".")
+	if integer+fraction == "" {
+		return "", strconv.ErrSyntax
+	}
+	for _, r := range integer + fraction {
+		if r < '0' || r > '9' {
+			return "", strconv.ErrSyntax
+		}
+	}
+	return v, nil
+}
+
+// insert insert batch of records, in a transaction of its own if set
+func (im *importer) insert(batch [][]any) error {
+	if im.opts.Transaction {
+		err := im.id.BeginTransaction()
+		if err != nil {
+			return err
+		}
+	}
+	fields := make([]string, 0, len(im.columns))
+	for _, c := range im.columns {
+		fields = append(fields, c.Name)
+	}
+	_, err := im.id.Insert(im.table, &common.Entries{Fields: fields, Values: batch})
+	if im.opts.Transaction {
+		if err != nil {
+			im.id.Rollback()
+			return err
+		}
+		err = im.id.Commit()
+	}
+	if err != nil {
+		return err
+	}
+	im.result.Imported += int64(len(batch))
+	log.Log.Debugf("Imported %d records into %s", im.result.Imported, im.table)
+	return nil
+}
+
+// value value of the input name, nil if NULL or not part of the record
+func (record *importRecord) value(name string) *string {
+	for i, n := range record.names {
+		if n == name {
+			return record.values[i]
+		}
+	}
+	return nil
+}
+
+// nullValue check if the text is a NULL marker
+func nullValue(opts *ImportOptions, v string) bool {
+	if len(opts.NullValues) == 0 {
+		return v == ""
+	}
+	for _, n := range opts.NullValues {
+		if n == v {
+			return true
+		}
+	}
+	return false
+}
+
+// csvSource CSV input with header line or header names of the options
+type csvSource struct {
+	reader *csv.Reader
+	opts   *ImportOptions
+	header []string
+}
+
+func newCSVSource(reader io.Reader, opts *ImportOptions) *csvSource {
+	cr := csv.NewReader(reader)
+	cr.FieldsPerRecord = -1
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	return &csvSource{reader: cr, opts: opts, header: opts.Header}
+}
+
+func (cs *csvSource) next() (*importRecord, error) {
+	if cs.header == nil {
+		header, err := cs.reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		cs.header = header
+	}
+	fields, err := cs.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return &importRecord{line: int64(parseErr.StartLine), err: parseErr.Err}, nil
+		}
+		return nil, err
+	}
+	line, _ := cs.reader.FieldPos(0)
+	record := &importRecord{line: int64(line), names: cs.header}
+	if len(fields) != len(cs.header) {
+		record.err = errorrepo.NewError("DB000061", len(fields), len(cs.header))
+		return record, nil
+	}
+	record.values = make([]*string, len(fields))
+	for i := range fields {
+		if !nullValue(cs.opts, fields[i]) {
+			record.values[i] = &fields[i]
+		}
+	}
+	return record, nil
+}
+
+// jsonSource JSON Lines input with one JSON object per line
+type jsonSource struct {
+	reader *bufio.Reader
+	opts   *ImportOptions
+	line   int64
+}
+
+func (js *jsonSource) next() (*importRecord, error) {
+	for {
+		data, err := js.reader.ReadBytes('\n')
+		if len(data) == 0 && err != nil {
+			return nil, err
+		}
+		js.line++
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+		record := &importRecord{line: js.line}
+		record.names, record.values, record.err = js.parse(data)
+		return record, nil
+	}
+}
+
+// parse parse JSON object keeping the order of the names. Values are provided
+// as text, nested objects and arrays as JSON text.
+func (js *jsonSource) parse(data []byte) ([]string, []*string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if token != json.Delim('{') {
+		return nil, nil, fmt.Errorf("JSON object expected")
+	}
+	names := make([]string, 0)
+	values := make([]*string, 0)
+	for decoder.More() {
+		token, err = decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		var raw json.RawMessage
+		err = decoder.Decode(&raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		names = append(names, token.(string))
+		var value any
+		err = json.Unmarshal(raw, &value)
+		if err != nil {
+			return nil, nil, err
+		}
+		var text string
+		switch v := value.(type) {
+		case nil:
+			values = append(values, nil)
+			continue
+		case string:
+			if nullValue(js.opts, v) && len(js.opts.NullValues) > 0 {
+				values = append(values, nil)
+				continue
+			}
+			text = v
+		case bool:
+			text = strconv.FormatBool(v)
+		default:
+			text = string(raw)
+		}
+		values = append(values, &text)
+	}
+	return names, values, nil
+}
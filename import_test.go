@@ -0,0 +1,127 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package flynn
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tknie/flynn/common"
+)
+
+func TestImportCSV(t *testing.T) {
+	InitLog(t)
+
+	db := newCopyDatabase(common.RegDbID(4730), common.PostgresType)
+	defer db.unregister()
+
+	input := "Id,First Name,Salary,Active,Birth,Skip,Created\n" +
+		"1,Anna,1200.50,true,1970-01-02,x,2024-02-03T04:05:06Z\n" +
+		"2,,NULL,false,1980-12-24,y,2024-02-03 10:00:00\n" +
+		"x3,Bob,10,true,1990-01-01,z,2024-02-03 10:00:00\n" +
+		"4,Carl,11,true\n" +
+		"5,\"Dora, D.\",12,false,2000-02-29,z,2024-02-03 10:00:00\n"
+	result, err := Import(db.id, "persons", strings.NewReader(input), common.CSVExport, &ImportOptions{
+		Infer:       true,
+		Mapping:     map[string]string{"Skip": ""},
+		Types:       map[string]common.DataType{"Id": common.Integer},
+		NullValues:  []string{"", "NULL"},
+		MaxErrors:   2,
+		BatchSize:   2,
+		Transaction: true,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, int64(3), result.Imported)
+	assert.Equal(t, int64(2), result.Skipped)
+	if assert.Len(t, result.Errors, 2) {
+		assert.Equal(t, "line 4: DB000060: value x3 of column Id not valid", result.Errors[0].Error())
+		assert.Equal(t, "line 5: DB000061: record has 4 fields but header 7", result.Errors[1].Error())
+	}
+	types := make([]common.DataType, 0)
+	names := make([]string, 0)
+	for _, c := range result.Columns {
+		names = append(names, c.Name)
+		types = append(types, c.DataType)
+	}
+	assert.Equal(t, []string{"Id", "First_Name", "Salary", "Active", "Birth", "Created"}, names)
+	assert.Equal(t, []common.DataType{common.Integer, common.Alpha, common.Float, common.Boolean,
+		common.Date, common.CurrentTimestamp}, types)
+	assert.Equal(t, []string{"begin", "commit", "begin", "commit"}, db.calls)
+	assert.Equal(t, 2, db.inserts)
+	rows := db.rows["persons"]
+	if assert.Len(t, rows, 3) {
+		assert.Equal(t, []any{int64(1), "Anna", 1200.5, true, time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)}, rows[0])
+		assert.Nil(t, rows[1][1])
+		assert.Nil(t, rows[1][2])
+		assert.Equal(t, "Dora, D.", rows[2][1])
+	}
+
+	_, err = Import(db.id, "persons", strings.NewReader(input), common.CSVExport, &ImportOptions{
+		Mapping: map[string]string{"Skip": ""}, NullValues: []string{"", "NULL"}})
+	assert.EqualError(t, err, "DB000059: import into persons failed at line 4: DB000060: value x3 of column Id not valid")
+}
+
+func TestImportJSONLines(t *testing.T) {
+	InitLog(t)
+
+	db := newCopyDatabase(common.RegDbID(4731), common.PostgresType)
+	defer db.unregister()
+	db.columns["events"] = []*common.Column{
+		{Name: "id", DataType: common.Integer, Length: 8},
+		{Name: "name", DataType: common.Alpha, Length: 20},
+		{Name: "day", DataType: common.Date},
+		{Name: "payload", DataType: common.Text},
+		{Name: "amount", DataType: common.Decimal, Length: 20, Digits: 2},
+	}
+
+	input := `{"id":1,"name":"a","day":"03.02.2024","payload":{"x":[1,2]},"amount":123456789012345678.91}` + "\n\n" +
+		`{"name":null,"id":2}` + "\n" +
+		`{"id":3,` + "\n" +
+		`{"id":4,"name":"d","day":"31.12.2023","payload":"text","amount":"-0.10"}` + "\n"
+	result, err := Import(db.id, "events", strings.NewReader(input), common.JSONLinesExport, &ImportOptions{
+		DateFormats: []string{"02.01.2006"},
+		MaxErrors:   1,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, int64(3), result.Imported)
+	assert.Nil(t, result.Columns)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, int64(4), result.Errors[0].Line)
+	}
+	assert.Equal(t, [][]any{
+		{int64(1), "a", time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC), `{"x":[1,2]}`, "123456789012345678.91"},
+		{int64(2), nil, nil, nil, nil},
+		{int64(4), "d", time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC), "text", "-0.10"},
+	}, db.rows["events"])
+	assert.Nil(t, db.calls)
+}
+
+func TestImportDecimalText(t *testing.T) {
+	InitLog(t)
+
+	for _, v := range []string{"1", " -12.50 ", "+.5", "7.", "123456789012345678901234567.89"} {
+		text, err := decimalText(v)
+		assert.NoError(t, err)
+		assert.Equal(t, strings.TrimSpace(v), text)
+	}
+	for _, v := range []string{"", ".", "--1", "1e5", "NaN", "1.2.3", "0x10"} {
+		_, err := decimalText(v)
+		assert.Error(t, err, v)
+	}
+}
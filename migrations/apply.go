@@ -0,0 +1,299 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package migrations
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tknie/errorrepo"
+	"github.com/tknie/flynn/common"
+	"github.com/tknie/log"
+)
+
+// lockRetry wait time until next try to get the migration lock
+var lockRetry = 500 * time.Millisecond
+
+// migrationRecord bookkeeping table entry, the version is stored as
+// string to be independent of the integer size of the database
+type migrationRecord struct {
+	Version   string `flynn:"Version:PRIMARY KEY:20"`
+	Name      string `flynn:"Name::255"`
+	Checksum  string `flynn:"Checksum::64"`
+	AppliedAt time.Time
+}
+
+// migrationLock lock table entry, only one entry can exist
+type migrationLock struct {
+	LockId   int    `flynn:"LockId:PRIMARY KEY"`
+	Owner    string `flynn:"Owner::255"`
+	LockedAt time.Time
+}
+
+// lockTable name of the lock table
+func (m *Migrator) lockTable() string {
+	return m.Table + "_lock"
+}
+
+// prepare create bookkeeping and lock table if not exists
+func (m *Migrator) prepare() error {
+	status, err := m.id.CreateTableIfNotExists(m.Table, &migrationRecord{})
+	if err != nil {
+		return err
+	}
+	log.Log.Debugf("Migration table %s: %v", m.Table, status)
+	status, err = m.id.CreateTableIfNotExists(m.lockTable(), &migrationLock{})
+	if err != nil {
+		return err
+	}
+	log.Log.Debugf("Migration lock table %s: %v", m.lockTable(), status)
+	return nil
+}
+
+// lock acquire migration lock waiting until lock timeout
+func (m *Migrator) lock() error {
+	dt := m.id.DriverType()
+	insert := "INSERT INTO " + m.lockTable() + " (LockId, Owner, LockedAt) VALUES (1, " +
+		dt.Placeholder(1) + ", " + dt.Placeholder(2) + ")"
+	hostname, _ := os.Hostname()
+	owner := fmt.Sprintf("%s:%d", hostname, os.Getpid())
+	deadline := time.Now().Add(m.LockTimeout)
+	for {
+		_, err := m.id.Execute(insert, owner, time.Now())
+		if err == nil {
+			log.Log.Debugf("Migration lock acquired by %s", owner)
+			return nil
+		}
+		log.Log.Debugf("Migration lock not acquired: %v", err)
+		if time.Now().After(deadline) {
+			return errorrepo.NewError("DB000039", m.lockTable(), m.LockTimeout)
+		}
+		time.Sleep(lockRetry)
+	}
+}
+
+// Unlock release the migration lock. Can be used to remove a lock left
+// by a crashed migration.
+func (m *Migrator) Unlock() error {
+	_, err := m.id.Execute("DELETE FROM " + m.lockTable() + " WHERE LockId = 1")
+	return err
+}
+
+// Applied all migrations applied to the database ordered by version
+func (m *Migrator) Applied() ([]*AppliedMigration, error) {
+	err := m.prepare()
+	if err != nil {
+		return nil, err
+	}
+	return m.applied()
+}
+
+func (m *Migrator) applied() ([]*AppliedMigration, error) {
+	applied := make([]*AppliedMigration, 0)
+	q := &common.Query{TableName: m.Table, DataStruct: &migrationRecord{},
+		Fields: []string{"*"}}
+	_, err := m.id.Query(q, func(search *common.Query, result *common.Result) error {
+		r := result.Data.(*migrationRecord)
+		version, err := strconv.ParseInt(strings.TrimSpace(r.Version), 10, 64)
+		if err != nil {
+			return err
+		}
+		applied = append(applied, &AppliedMigration{Version: version, Name: r.Name,
+			Checksum: r.Checksum, AppliedAt: r.AppliedAt})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(applied, func(i, j int) bool {
+		return applied[i].Version < applied[j].Version
+	})
+	return applied, nil
+}
+
+// Pending all registered migrations not applied to the database
+func (m *Migrator) Pending() ([]*Migration, error) {
+	applied, err := m.Applied()
+	if err != nil {
+		return nil, err
+	}
+	appliedMap, err := m.verify(applied)
+	if err != nil {
+		return nil, err
+	}
+	pending := make([]*Migration, 0)
+	for _, mg := range m.migrations {
+		if _, ok := appliedMap[mg.Version]; !ok {
+			pending = append(pending, mg)
+		}
+	}
+	return pending, nil
+}
+
+// verify check checksums of applied migrations against registered ones
+func (m *Migrator) verify(applied []*AppliedMigration) (map[int64]*AppliedMigration, error) {
+	appliedMap := make(map[int64]*AppliedMigration)
+	for _, a := range applied {
+		appliedMap[a.Version] = a
+		mg := m.migration(a.Version)
+		if mg == nil {
+			log.Log.Infof("Applied migration %d_%s not registered", a.Version, a.Name)
+			continue
+		}
+		if mg.Checksum != a.Checksum {
+			return nil, errorrepo.NewError("DB000037", mg.String(), a.Checksum, mg.Checksum)
+		}
+	}
+	return appliedMap, nil
+}
+
+// migration search registered migration of the version
+func (m *Migrator) migration(version int64) *Migration {
+	for _, mg := range m.migrations {
+		if mg.Version == version {
+			return mg
+		}
+	}
+	return nil
+}
+
+// Up apply all pending migrations
+func (m *Migrator) Up() ([]*Migration, error) {
+	return m.UpTo(math.MaxInt64)
+}
+
+// UpTo apply all pending migrations up to the given version. Each migration
+// is applied in its own transaction. Databases like MySQL or Oracle commit
+// DDL statements implicitly, so failed migrations may be applied partly.
+func (m *Migrator) UpTo(version int64) (done []*Migration, err error) {
+	err = m.prepare()
+	if err != nil {
+		return nil, err
+	}
+	err = m.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer m.Unlock()
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	appliedMap, err := m.verify(applied)
+	if err != nil {
+		return nil, err
+	}
+	done = make([]*Migration, 0)
+	for _, mg := range m.migrations {
+		if mg.Version > version {
+			break
+		}
+		if _, ok := appliedMap[mg.Version]; ok {
+			continue
+		}
+		err = m.run(mg, true)
+		if err != nil {
+			return done, err
+		}
+		done = append(done, mg)
+	}
+	return done, nil
+}
+
+// Down revert the given number of last applied migrations
+func (m *Migrator) Down(steps int) (done []*Migration, err error) {
+	err = m.prepare()
+	if err != nil {
+		return nil, err
+	}
+	err = m.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer m.Unlock()
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	_, err = m.verify(applied)
+	if err != nil {
+		return nil, err
+	}
+	done = make([]*Migration, 0)
+	for i := len(applied) - 1; i >= 0 && len(done) < steps; i-- {
+		mg := m.migration(applied[i].Version)
+		if mg == nil || (mg.Down == nil && strings.TrimSpace(mg.DownSQL) == "") {
+			return done, errorrepo.NewError("DB000041", applied[i].Version)
+		}
+		err = m.run(mg, false)
+		if err != nil {
+			return done, err
+		}
+		done = append(done, mg)
+	}
+	return done, nil
+}
+
+// run apply or revert migration and adapt bookkeeping in one transaction
+func (m *Migrator) run(mg *Migration, up bool) (err error) {
+	log.Log.Debugf("Run migration %s up=%v", mg, up)
+	err = m.id.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			log.Log.Errorf("Migration %s failed: %v", mg, err)
+			m.id.Rollback()
+		}
+	}()
+	dt := m.id.DriverType()
+	if up {
+		err = execute(m.id, mg.Up, mg.UpSQL)
+		if err != nil {
+			return err
+		}
+		_, err = m.id.Execute("INSERT INTO "+m.Table+" (Version, Name, Checksum, AppliedAt) VALUES ("+
+			dt.Placeholder(1)+", "+dt.Placeholder(2)+", "+dt.Placeholder(3)+", "+dt.Placeholder(4)+")",
+			strconv.FormatInt(mg.Version, 10), mg.Name, mg.Checksum, time.Now())
+	} else {
+		err = execute(m.id, mg.Down, mg.DownSQL)
+		if err != nil {
+			return err
+		}
+		_, err = m.id.Execute("DELETE FROM "+m.Table+" WHERE Version = "+dt.Placeholder(1),
+			strconv.FormatInt(mg.Version, 10))
+	}
+	if err != nil {
+		return err
+	}
+	return m.id.Commit()
+}
+
+// execute call migration function or execute all SQL statements of the script
+func execute(id common.RegDbID, fct MigrateFunction, script string) error {
+	if fct != nil {
+		return fct(id)
+	}
+	for _, statement := range common.SplitStatements(script, id.DriverType() == common.OracleType) {
+		_, err := id.Execute(statement)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package migrations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tknie/errorrepo"
+	"github.com/tknie/flynn/common"
+	"github.com/tknie/log"
+)
+
+// DefaultTable default bookkeeping table of applied migrations
+const DefaultTable = "flynn_migrations"
+
+// DefaultLockTimeout default time waiting for the migration lock
+const DefaultLockTimeout = 2 * time.Minute
+
+// MigrateFunction Go migration function called inside the migration transaction
+type MigrateFunction func(id common.RegDbID) error
+
+// Migration one versioned schema migration step. It is either defined by Go
+// functions or by SQL statements.
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       MigrateFunction
+	Down     MigrateFunction
+	UpSQL    string
+	DownSQL  string
+	Checksum string
+}
+
+// AppliedMigration bookkeeping entry of a migration applied to the database
+type AppliedMigration struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Migrator migration handler of a database registered with a RegDbID
+type Migrator struct {
+	id          common.RegDbID
+	Table       string
+	LockTimeout time.Duration
+	migrations  []*Migration
+}
+
+// New create new migrator for the given database using the default
+// bookkeeping table
+func New(id common.RegDbID) *Migrator {
+	return &Migrator{id: id, Table: DefaultTable, LockTimeout: DefaultLockTimeout}
+}
+
+// Register register Go function migration. The down function is optional.
+func (m *Migrator) Register(version int64, name string, up, down MigrateFunction) error {
+	if up == nil {
+		return errorrepo.NewError("DB000042", version)
+	}
+	return m.add(&Migration{Version: version, Name: name, Up: up, Down: down})
+}
+
+// RegisterSQL register SQL migration. The down SQL is optional.
+func (m *Migrator) RegisterSQL(version int64, name, upSQL, downSQL string) error {
+	if strings.TrimSpace(upSQL) == "" {
+		return errorrepo.NewError("DB000042", version)
+	}
+	return m.add(&Migration{Version: version, Name: name, UpSQL: upSQL, DownSQL: downSQL})
+}
+
+// LoadFS load SQL migrations out of the directory of the file system. The file
+// names need to be like '0001_create_album.up.sql' and
+// '0001_create_album.down.sql'. Files ending with '.sql' only are up migrations.
+func (m *Migrator) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	loaded := make(map[int64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+		version, name, up, err := parseFileName(e.Name())
+		if err != nil {
+			return err
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		mg, ok := loaded[version]
+		if !ok {
+			mg = &Migration{Version: version, Name: name}
+			loaded[version] = mg
+		}
+		if up {
+			mg.UpSQL = string(content)
+		} else {
+			mg.DownSQL = string(content)
+		}
+	}
+	for _, mg := range loaded {
+		if strings.TrimSpace(mg.UpSQL) == "" {
+			return errorrepo.NewError("DB000042", mg.Version)
+		}
+		err = m.add(mg)
+		if err != nil {
+			return err
+		}
+	}
+	log.Log.Debugf("Loaded %d migrations out of %s", len(loaded), dir)
+	return nil
+}
+
+// Migrations all registered migrations ordered by version
+func (m *Migrator) Migrations() []*Migration {
+	return m.migrations
+}
+
+// add add migration keeping the version order
+func (m *Migrator) add(mg *Migration) error {
+	for _, r := range m.migrations {
+		if r.Version == mg.Version {
+			return errorrepo.NewError("DB000038", mg.Version)
+		}
+	}
+	if mg.Checksum == "" {
+		mg.Checksum = mg.checksum()
+	}
+	m.migrations = append(m.migrations, mg)
+	sort.Slice(m.migrations, func(i, j int) bool {
+		return m.migrations[i].Version < m.migrations[j].Version
+	})
+	return nil
+}
+
+// checksum calculate checksum of the up migration. Go function migrations
+// are identified by version and name only.
+func (mg *Migration) checksum() string {
+	content := mg.UpSQL
+	if mg.Up != nil {
+		content = fmt.Sprintf("%d:%s", mg.Version, mg.Name)
+	}
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// String migration display name
+func (mg *Migration) String() string {
+	return fmt.Sprintf("%d_%s", mg.Version, mg.Name)
+}
+
+// parseFileName parse version, name and direction out of the file name
+func parseFileName(fileName string) (version int64, name string, up bool, err error) {
+	base := strings.TrimSuffix(fileName, ".sql")
+	up = true
+	switch {
+	case strings.HasSuffix(base, ".up"):
+		base = strings.TrimSuffix(base, ".up")
+	case strings.HasSuffix(base, ".down"):
+		base = strings.TrimSuffix(base, ".down")
+		up = false
+	}
+	v, name, _ := strings.Cut(base, "_")
+	version, err = strconv.ParseInt(v, 10, 64)
+	if err != nil || version < 0 {
+		return 0, "", false, errorrepo.NewError("DB000040", fileName)
+	}
+	return version, name, up, nil
+}
@@ -0,0 +1,173 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/tknie/flynn"
+	"github.com/tknie/flynn/common"
+	"github.com/tknie/log"
+)
+
+var logRus = logrus.StandardLogger()
+var once = new(sync.Once)
+
+func InitLog(t *testing.T) {
+	once.Do(startLog)
+	log.Log.Debugf("TEST: %s", t.Name())
+}
+
+func startLog() {
+	fileName := "db.trace.log"
+	level := os.Getenv("ENABLE_DB_DEBUG")
+	logLevel := logrus.WarnLevel
+	switch level {
+	case "debug", "1":
+		log.SetDebugLevel(true)
+		logLevel = logrus.DebugLevel
+	case "info", "2":
+		log.SetDebugLevel(false)
+		logLevel = logrus.InfoLevel
+	default:
+	}
+	logRus.SetLevel(logLevel)
+	p := os.Getenv("LOGPATH")
+	if p == "" {
+		p = os.TempDir()
+	}
+	f, err := os.OpenFile(p+"/"+fileName, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		fmt.Println("Error opening log:", err)
+		return
+	}
+	logRus.SetOutput(f)
+	log.Log = logRus
+}
+
+var testFS = fstest.MapFS{
+	"sql/0002_add_index.up.sql":      {Data: []byte("CREATE INDEX migtest_name_idx ON migtest (name);")},
+	"sql/0002_add_index.down.sql":    {Data: []byte("DROP INDEX migtest_name_idx;")},
+	"sql/0001_create_table.sql":      {Data: []byte("CREATE TABLE migtest (id INTEGER, name VARCHAR(20));\n-- comment; ignored\nINSERT INTO migtest VALUES (1, 'a;b');")},
+	"sql/0001_create_table.down.sql": {Data: []byte("DROP TABLE migtest")},
+	"sql/README.md":                  {Data: []byte("no migration")},
+}
+
+func TestMigrationFileName(t *testing.T) {
+	InitLog(t)
+	version, name, up, err := parseFileName("0012_create_album.up.sql")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), version)
+	assert.Equal(t, "create_album", name)
+	assert.True(t, up)
+	version, _, up, err = parseFileName("20240101120000_drop.down.sql")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20240101120000), version)
+	assert.False(t, up)
+	_, _, _, err = parseFileName("create_album.sql")
+	assert.Error(t, err)
+}
+
+func TestMigrationSplit(t *testing.T) {
+	InitLog(t)
+	assert.Equal(t, []string{"CREATE TABLE x (id INTEGER)", "INSERT INTO x VALUES ('a;b')", "SELECT \"c;d\" FROM x"},
+		common.SplitStatements("CREATE TABLE x (id INTEGER);\n-- drop; later\nINSERT INTO x VALUES ('a;b');;\nSELECT \"c;d\" FROM x", false))
+	assert.Empty(t, common.SplitStatements(" ;\n-- only comment\n", false))
+}
+
+func TestMigrationLoadFS(t *testing.T) {
+	InitLog(t)
+	m := New(1)
+	err := m.LoadFS(testFS, "sql")
+	if !assert.NoError(t, err) {
+		return
+	}
+	migrations := m.Migrations()
+	if !assert.Len(t, migrations, 2) {
+		return
+	}
+	assert.Equal(t, "1_create_table", migrations[0].String())
+	assert.Equal(t, "DROP TABLE migtest", migrations[0].DownSQL)
+	assert.Equal(t, int64(2), migrations[1].Version)
+	assert.Len(t, migrations[0].Checksum, 64)
+	assert.NotEqual(t, migrations[0].Checksum, migrations[1].Checksum)
+
+	err = m.RegisterSQL(2, "duplicate", "SELECT 1", "")
+	assert.Error(t, err)
+	err = m.LoadFS(fstest.MapFS{"sql/0003_down_only.down.sql": {Data: []byte("DROP TABLE x")}}, "sql")
+	assert.Error(t, err)
+}
+
+func TestMigrationRegister(t *testing.T) {
+	InitLog(t)
+	m := New(1)
+	up := func(id common.RegDbID) error { return nil }
+	assert.NoError(t, m.Register(3, "third", up, nil))
+	assert.NoError(t, m.RegisterSQL(1, "first", "SELECT 1", ""))
+	assert.Error(t, m.Register(4, "no up", nil, nil))
+	assert.Error(t, m.RegisterSQL(5, "empty", " ", ""))
+	migrations := m.Migrations()
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, int64(3), migrations[1].Version)
+
+	other := New(1)
+	assert.NoError(t, other.Register(3, "third", func(id common.RegDbID) error { return fmt.Errorf("other") }, nil))
+	assert.Equal(t, migrations[1].Checksum, other.Migrations()[0].Checksum)
+
+	_, err := m.verify([]*AppliedMigration{{Version: 1, Checksum: "changed"}})
+	assert.Error(t, err)
+	applied, err := m.verify([]*AppliedMigration{{Version: 3, Checksum: migrations[1].Checksum}, {Version: 9}})
+	assert.NoError(t, err)
+	assert.Len(t, applied, 2)
+}
+
+func TestMigrationPostgres(t *testing.T) {
+	InitLog(t)
+	postgresHost := os.Getenv("POSTGRES_HOST")
+	if postgresHost == "" {
+		t.Skip("Postgres Host not set")
+	}
+	url := fmt.Sprintf("postgres://%s:%s@%s:%s/%s", "admin", os.Getenv("POSTGRES_PWD"),
+		postgresHost, os.Getenv("POSTGRES_PORT"), "bitgarten")
+	id, err := flynn.Handle("postgres", url)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer id.FreeHandler()
+	m := New(id)
+	m.Table = "migtest_migrations"
+	if !assert.NoError(t, m.LoadFS(testFS, "sql")) {
+		return
+	}
+	done, err := m.Up()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, done, 2)
+	pending, err := m.Pending()
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+	applied, err := m.Applied()
+	assert.NoError(t, err)
+	assert.Len(t, applied, 2)
+	done, err = m.Down(2)
+	assert.NoError(t, err)
+	assert.Len(t, done, 2)
+	id.DeleteTable(m.Table)
+	id.DeleteTable(m.lockTable())
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
 	"math"
 	"strings"
 
@@ -211,6 +212,11 @@ func (mysql *Mysql) ByteArrayAvailable() bool {
 	return false
 }
 
+// DriverType database driver type used for dialect specific SQL
+func (mysql *Mysql) DriverType() common.ReferenceType {
+	return common.MysqlType
+}
+
 // Reference reference to mysql URL
 func (mysql *Mysql) Reference() (string, string) {
 	return "mysql", mysql.generateURL()
@@ -281,6 +287,11 @@ func (mysql *Mysql) Delete(name string, remove *common.Entries) (int64, error) {
 	return dbsql.Delete(mysql, name, remove)
 }
 
+// TableColumns get table column definitions
+func (mysql *Mysql) TableColumns(tableName string) ([]*common.Column, error) {
+	return dbsql.TableColumns(mysql, tableName)
+}
+
 // GetTableColumn get table columne names
 func (mysql *Mysql) GetTableColumn(tableName string) ([]string, error) {
 	log.Log.Debugf("Get table column ...")
@@ -343,11 +354,21 @@ func (mysql *Mysql) CreateTable(name string, columns any) error {
 	return dbsql.CreateTable(mysql, name, columns)
 }
 
-// AdaptTable create a new table
+// AdaptTable adapt table to new structure
 func (mysql *Mysql) AdaptTable(name string, newStruct any) error {
 	return dbsql.AdaptTable(mysql, name, newStruct)
 }
 
+// PlanAdaptTable generate plan to adapt table to new structure
+func (mysql *Mysql) PlanAdaptTable(name string, newStruct any) (*common.AdaptPlan, error) {
+	return dbsql.PlanAdaptTable(mysql, name, newStruct)
+}
+
+// ApplyAdaptPlan apply adapt plan to table
+func (mysql *Mysql) ApplyAdaptPlan(plan *common.AdaptPlan, destructive bool) error {
+	return dbsql.ApplyAdaptPlan(mysql, plan, destructive)
+}
+
 // DeleteTable delete a table
 func (mysql *Mysql) DeleteTable(name string) error {
 	return dbsql.DeleteTable(mysql, name)
@@ -368,6 +389,11 @@ func (mysql *Mysql) Batch(batch string) error {
 	return dbsql.Batch(mysql, batch)
 }
 
+// Execute execute SQL statement with parameters
+func (mysql *Mysql) Execute(statement string, args ...any) (int64, error) {
+	return dbsql.Execute(mysql, statement, args...)
+}
+
 // BatchSelect batch SQL query in table with values returned
 func (mysql *Mysql) BatchSelect(batch string) ([][]interface{}, error) {
 	return dbsql.BatchSelect(mysql, batch)
@@ -391,7 +417,7 @@ func (mysql *Mysql) BatchSelectFct(search *common.Query, fct common.ResultFuncti
 	if search.DataStruct == nil {
 		_, err = search.ParseRows(rows, fct)
 	} else {
-		ti := common.CreateInterface(search.DataStruct, search.Fields)
+		ti := common.CreateInterfaceNaming(search.DataStruct, search.Fields, search.Naming)
 		search.TypeInfo = ti
 		_, err = search.ParseStruct(rows, fct)
 	}
@@ -487,3 +513,28 @@ func (mysql *Mysql) Stream(search *common.Query, sf common.StreamFunction) error
 	}
 	return nil
 }
+
+// StreamWrite write the reader data in blocks into the field of the records
+// matching the search, following blocks are appended using CONCAT
+func (mysql *Mysql) StreamWrite(search *common.Query, reader io.Reader) (int64, error) {
+	field := search.Fields[0]
+	return dbsql.StreamWrite(mysql, search, reader, func(first bool, block []byte) (string, []any) {
+		if first {
+			return fmt.Sprintf("UPDATE %s SET %s=? WHERE %s", search.TableName, field, search.Search), []any{block}
+		}
+		return fmt.Sprintf("UPDATE %s SET %s=CONCAT(%s,?) WHERE %s", search.TableName, field, field, search.Search),
+			[]any{block}
+	})
+}
+
+// OpenLOB open reader of the LOB field of the search, blocks are read
+// using SUBSTRING
+func (mysql *Mysql) OpenLOB(search *common.Query) (common.LOBReader, error) {
+	field := search.Fields[0]
+	return dbsql.OpenLOB(mysql, search,
+		fmt.Sprintf("SELECT LENGTH(%s) FROM %s WHERE %s", field, search.TableName, search.Search),
+		func(offset int64, length int32) string {
+			return fmt.Sprintf("SELECT SUBSTRING(%s FROM %d FOR %d) FROM %s WHERE %s",
+				field, offset+1, length, search.TableName, search.Search)
+		})
+}
@@ -15,6 +15,7 @@
 package mysql
 
 import (
+	"io"
 	"math"
 
 	"github.com/tknie/errorrepo"
@@ -60,6 +61,11 @@ func (ada *mysql) URL() string {
 	return ""
 }
 
+// DriverType database driver type used for dialect specific SQL
+func (ada *mysql) DriverType() common.ReferenceType {
+	return common.MysqlType
+}
+
 // Maps database maps, tables or views
 func (ada *mysql) Maps() ([]string, error) {
 	return nil, errorrepo.NewError("DB065535")
@@ -94,6 +100,11 @@ func (ada *mysql) Delete(name string, remove *common.Entries) (int64, error) {
 	return 0, errorrepo.NewError("DB065535")
 }
 
+// TableColumns get table column definitions
+func (ada *mysql) TableColumns(tableName string) ([]*common.Column, error) {
+	return nil, errorrepo.NewError("DB065535")
+}
+
 // GetTableColumn get table columne names
 func (ada *mysql) GetTableColumn(tableName string) ([]string, error) {
 	return nil, errorrepo.NewError("DB065535")
@@ -115,6 +126,16 @@ func (ada *mysql) AdaptTable(string, any) error {
 	return errorrepo.NewError("DB065535")
 }
 
+// PlanAdaptTable generate plan to adapt table to new structure
+func (ada *mysql) PlanAdaptTable(string, any) (*common.AdaptPlan, error) {
+	return nil, errorrepo.NewError("DB065535")
+}
+
+// ApplyAdaptPlan apply adapt plan to table
+func (ada *mysql) ApplyAdaptPlan(*common.AdaptPlan, bool) error {
+	return errorrepo.NewError("DB065535")
+}
+
 // DeleteTable delete a table
 func (ada *mysql) DeleteTable(string) error {
 	return errorrepo.NewError("DB065535")
@@ -125,6 +146,11 @@ func (ada *mysql) Batch(batch string) error {
 	return errorrepo.NewError("DB065535")
 }
 
+// Execute execute SQL statement with parameters
+func (ada *mysql) Execute(statement string, args ...any) (int64, error) {
+	return -1, errorrepo.NewError("DB065535")
+}
+
 // BatchSelect batch SQL query in table with values returned
 func (ada *mysql) BatchSelect(batch string) ([][]interface{}, error) {
 	return nil, errorrepo.NewError("DB065535")
@@ -150,3 +176,11 @@ func (ada *mysql) Rollback() error {
 func (ada *mysql) Stream(search *common.Query, sf common.StreamFunction) error {
 	return errorrepo.NewError("DB065535")
 }
+
+func (ada *mysql) StreamWrite(search *common.Query, reader io.Reader) (int64, error) {
+	return 0, errorrepo.NewError("DB065535")
+}
+
+func (ada *mysql) OpenLOB(search *common.Query) (common.LOBReader, error) {
+	return nil, errorrepo.NewError("DB065535")
+}
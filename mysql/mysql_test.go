@@ -18,10 +18,12 @@ import (
 	"strconv"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/tknie/flynn/common"
+	"github.com/tknie/flynn/dbsql"
 	"github.com/tknie/log"
 )
 
@@ -118,3 +120,22 @@ func TestMysqlCall(t *testing.T) {
 		})
 	assert.NoError(t, err)
 }
+
+func TestMysqlTypeMapper(t *testing.T) {
+	InitLog(t)
+
+	columns, err := dbsql.CreateTableByStruct(typeMapper, &struct {
+		Name    string `flynn:"::40"`
+		Long    string `flynn:"::20000"`
+		Small   uint8
+		Big     uint64
+		Price   float64
+		Flag    bool
+		Data    []byte
+		Created time.Time
+	}{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Name VARCHAR(40) NOT NULL, Long LONGTEXT NOT NULL, Small TINYINT UNSIGNED NOT NULL, "+
+		"Big BIGINT UNSIGNED NOT NULL, Price DOUBLE NOT NULL, Flag BOOLEAN NOT NULL, Data LONGBLOB, "+
+		"Created DATETIME(6) NOT NULL", columns)
+}
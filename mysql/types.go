@@ -0,0 +1,63 @@
+//go:build !flynn_nomysql
+// +build !flynn_nomysql
+
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package mysql
+
+import (
+	"reflect"
+
+	"github.com/tknie/flynn/common"
+)
+
+// typeMapper MySQL native types
+var typeMapper = &common.DialectTypes{
+	Types: map[common.DataType]string{
+		common.Alpha:            "VARCHAR(%d)",
+		common.Text:             "LONGTEXT",
+		common.Unicode:          "VARCHAR(%d) CHARACTER SET utf8mb4",
+		common.Integer:          "INT",
+		common.Decimal:          "DECIMAL(%d,%d)",
+		common.Number:           "BIGINT",
+		common.Bit:              "BIT(%d)",
+		common.Bytes:            "LONGBLOB",
+		common.CurrentTimestamp: "DATETIME(6)",
+		common.Date:             "DATE",
+		common.BLOB:             "LONGBLOB",
+		common.Character:        "CHAR(%d)",
+		common.Float:            "DOUBLE",
+		common.Boolean:          "BOOLEAN",
+	},
+	Kinds: map[reflect.Kind]string{
+		reflect.Int8:    "TINYINT",
+		reflect.Int16:   "SMALLINT",
+		reflect.Int32:   "INT",
+		reflect.Int:     "BIGINT",
+		reflect.Int64:   "BIGINT",
+		reflect.Uint8:   "TINYINT UNSIGNED",
+		reflect.Uint16:  "SMALLINT UNSIGNED",
+		reflect.Uint32:  "INT UNSIGNED",
+		reflect.Uint:    "BIGINT UNSIGNED",
+		reflect.Uint64:  "BIGINT UNSIGNED",
+		reflect.Float32: "FLOAT",
+		reflect.Float64: "DOUBLE",
+		reflect.Bool:    "BOOLEAN",
+	},
+	// VARCHAR limit of utf8mb4 row size
+	MaxAlpha: 16383,
+}
+
+// TypeMapper dialect type mapper used to create tables
+func (mysql *Mysql) TypeMapper() common.TypeMapper {
+	return typeMapper
+}
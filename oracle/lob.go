@@ -0,0 +1,271 @@
+//go:build !flynn_nooracle
+// +build !flynn_nooracle
+
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package oracle
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tknie/errorrepo"
+	"github.com/tknie/flynn/common"
+	"github.com/tknie/flynn/dbsql"
+	"github.com/tknie/log"
+)
+
+const (
+	// lobBlocksize maximal block size of RAW buffers read from or
+	// appended to BLOBs in PL/SQL
+	lobBlocksize = 32767
+	// clobBlocksize maximal number of characters read from CLOBs, four
+	// byte characters need to fit into a PL/SQL VARCHAR2 buffer
+	clobBlocksize = lobBlocksize / utf8.UTFMax
+)
+
+// lobStatements LOB access statements of a field using DBMS_LOB
+type lobStatements struct {
+	table  string
+	field  string
+	search string
+	clob   bool
+}
+
+// newLobStatements LOB statements of the first field of the search
+func newLobStatements(search *common.Query, clob bool) *lobStatements {
+	return &lobStatements{table: search.TableName, field: search.Fields[0],
+		search: search.Search, clob: clob}
+}
+
+// blocksize block size used for the query block size, the size is given
+// in characters for CLOBs
+func (ls *lobStatements) blocksize(blocksize int32) int32 {
+	max := int32(lobBlocksize)
+	if ls.clob {
+		max = clobBlocksize
+	}
+	if blocksize <= 0 || blocksize > max {
+		return max
+	}
+	return blocksize
+}
+
+// length statement selecting LOB length, in characters for CLOBs
+func (ls *lobStatements) length() string {
+	return fmt.Sprintf("SELECT DBMS_LOB.GETLENGTH(%s) FROM %s WHERE %s", ls.field, ls.table, ls.search)
+}
+
+// substr PL/SQL block returning the LOB part of the amount (:2) at the
+// 1-based offset (:3) in the out parameter :1
+func (ls *lobStatements) substr() string {
+	return fmt.Sprintf("DECLARE l %s.%s%%TYPE; BEGIN SELECT %s INTO l FROM %s WHERE %s AND ROWNUM = 1; "+
+		":1 := DBMS_LOB.SUBSTR(l, :2, :3); END;", ls.table, ls.field, ls.field, ls.table, ls.search)
+}
+
+// empty statement setting the LOB to an empty LOB
+func (ls *lobStatements) empty() string {
+	emptyLob := "EMPTY_BLOB()"
+	if ls.clob {
+		emptyLob = "EMPTY_CLOB()"
+	}
+	return fmt.Sprintf("UPDATE %s SET %s=%s WHERE %s", ls.table, ls.field, emptyLob, ls.search)
+}
+
+// writeAppend PL/SQL block appending the buffer (:2) of the amount (:1)
+// to the LOBs of all records found
+func (ls *lobStatements) writeAppend() string {
+	return fmt.Sprintf("DECLARE l %s.%s%%TYPE; BEGIN FOR r IN (SELECT %s FROM %s WHERE %s FOR UPDATE) LOOP "+
+		"l := r.%s; DBMS_LOB.WRITEAPPEND(l, :1, :2); END LOOP; END;",
+		ls.table, ls.field, ls.field, ls.table, ls.search, ls.field)
+}
+
+// block statement and parameters writing the block, the first block
+// empties the LOB before
+func (ls *lobStatements) block(first bool, block []byte) (string, []any) {
+	if len(block) == 0 {
+		if first {
+			return ls.empty(), nil
+		}
+		return "", nil
+	}
+	args := []any{len(block), block}
+	if ls.clob {
+		args = []any{utf8.RuneCount(block), string(block)}
+	}
+	if first {
+		return "BEGIN " + ls.empty() + "; " + ls.writeAppend() + " END;", args
+	}
+	return ls.writeAppend(), args
+}
+
+// read read LOB part of the amount at the 0-based offset, CLOB text is
+// returned UTF-8 encoded
+func (ls *lobStatements) read(db *sql.DB, offset int64, amount int32) ([]byte, error) {
+	if ls.clob {
+		var text sql.NullString
+		_, err := db.Exec(ls.substr(), sql.Out{Dest: &text}, amount, offset+1)
+		return []byte(text.String), err
+	}
+	data := make([]byte, 0, amount)
+	_, err := db.Exec(ls.substr(), sql.Out{Dest: &data}, amount, offset+1)
+	return data, err
+}
+
+// columnTypeQuery statement and arguments selecting the data type of the
+// field, tables qualified by an owner are searched in the columns of the owner
+func columnTypeQuery(table, field string) (string, []any) {
+	if owner, name, found := strings.Cut(table, "."); found {
+		return `SELECT data_type FROM all_tab_columns WHERE owner = :1 AND table_name = :2 AND column_name = :3`,
+			[]any{strings.ToUpper(owner), strings.ToUpper(name), strings.ToUpper(field)}
+	}
+	return `SELECT data_type FROM user_tab_columns WHERE table_name = :1 AND column_name = :2`,
+		[]any{strings.ToUpper(table), strings.ToUpper(field)}
+}
+
+// isClob check if the field is a character LOB
+func isClob(db *sql.DB, table, field string) (bool, error) {
+	query, args := columnTypeQuery(table, field)
+	var dataType string
+	err := db.QueryRow(query, args...).Scan(&dataType)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	log.Log.Debugf("LOB field %s of %s has type %s", field, table, dataType)
+	return dataType == "CLOB" || dataType == "NCLOB", nil
+}
+
+// lobSize LOB length of the first record found
+func (ls *lobStatements) lobSize(db *sql.DB) (int64, error) {
+	var size sql.NullInt64
+	err := db.QueryRow(ls.length()).Scan(&size)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errorrepo.NewError("DB000022")
+		}
+		return 0, err
+	}
+	return size.Int64, nil
+}
+
+// Stream read the LOB field of the search in blocks of the query block
+// size using DBMS_LOB. CLOBs are read in blocks of characters and provided
+// UTF-8 encoded.
+func (oracle *Oracle) Stream(search *common.Query, sf common.StreamFunction) error {
+	dbOpen, err := oracle.Open()
+	if err != nil {
+		return err
+	}
+	defer oracle.Close()
+
+	db := dbOpen.(*sql.DB)
+	clob, err := isClob(db, search.TableName, search.Fields[0])
+	if err != nil {
+		return err
+	}
+	ls := newLobStatements(search, clob)
+	blocksize := ls.blocksize(search.Blocksize)
+	size, err := ls.lobSize(db)
+	if err != nil {
+		return err
+	}
+	log.Log.Debugf("Start stream for %s for %s size %d", search.Fields[0], search.TableName, size)
+	offset := int64(0)
+	for {
+		stream := &common.Stream{}
+		if offset < size {
+			stream.Data, err = ls.read(db, offset, blocksize)
+			if err != nil {
+				log.Log.Errorf("Stream read error: %v", err)
+				return err
+			}
+		}
+		err = sf(search, stream)
+		if err != nil {
+			log.Log.Errorf("stream function error: %s", err)
+			return err
+		}
+		offset += int64(blocksize)
+		if offset >= size {
+			break
+		}
+	}
+	return nil
+}
+
+// StreamWrite write the reader data in blocks into the field of the records
+// matching the search, blocks are appended using DBMS_LOB.WRITEAPPEND.
+// Text written into CLOBs needs to be UTF-8 encoded. The matching records
+// are counted before, the PL/SQL blocks do not report updated rows.
+func (oracle *Oracle) StreamWrite(search *common.Query, reader io.Reader) (int64, error) {
+	dbOpen, err := oracle.open()
+	if err != nil {
+		return 0, err
+	}
+	clob, err := isClob(dbOpen.(*sql.DB), search.TableName, search.Fields[0])
+	if err != nil {
+		if !oracle.IsTransaction() {
+			oracle.Close()
+		}
+		return 0, err
+	}
+	ls := newLobStatements(search, clob)
+	// the block size is adapted in a copy to keep the query of the caller
+	query := *search
+	if query.Blocksize <= 0 || query.Blocksize > lobBlocksize {
+		query.Blocksize = lobBlocksize
+	}
+	if clob {
+		// text blocks may be extended by an incomplete character
+		if query.Blocksize > lobBlocksize-utf8.UTFMax+1 {
+			query.Blocksize = lobBlocksize - utf8.UTFMax + 1
+		}
+		return dbsql.StreamWriteText(oracle, &query, reader, ls.block)
+	}
+	return dbsql.StreamWrite(oracle, &query, reader, ls.block)
+}
+
+// OpenLOB open reader of the BLOB field of the search, blocks are read
+// using DBMS_LOB.SUBSTR. CLOBs are not supported because character offsets
+// cannot be mapped to byte positions, use Stream instead.
+func (oracle *Oracle) OpenLOB(search *common.Query) (common.LOBReader, error) {
+	layer, url := oracle.Reference()
+	db, err := sql.Open(layer, url)
+	if err != nil {
+		return nil, err
+	}
+	clob, err := isClob(db, search.TableName, search.Fields[0])
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if clob {
+		db.Close()
+		return nil, errorrepo.NewError("DB000054", search.Fields[0])
+	}
+	ls := newLobStatements(search, false)
+	size, err := ls.lobSize(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return common.NewLOBReader(size, ls.blocksize(search.Blocksize), func(offset int64, length int32) ([]byte, error) {
+		return ls.read(db, offset, length)
+	}, db.Close), nil
+}
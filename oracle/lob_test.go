@@ -0,0 +1,63 @@
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package oracle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tknie/flynn/common"
+)
+
+func TestLobStatements(t *testing.T) {
+	InitLog(t)
+
+	search := &common.Query{TableName: "LOBTEST", Fields: []string{"DATA"}, Search: "ID=1"}
+	ls := newLobStatements(search, false)
+	assert.Equal(t, int32(lobBlocksize), ls.blocksize(0))
+	assert.Equal(t, int32(lobBlocksize), ls.blocksize(1000000))
+	assert.Equal(t, int32(4096), ls.blocksize(4096))
+	assert.Equal(t, "SELECT DBMS_LOB.GETLENGTH(DATA) FROM LOBTEST WHERE ID=1", ls.length())
+	assert.Equal(t, "DECLARE l LOBTEST.DATA%TYPE; BEGIN SELECT DATA INTO l FROM LOBTEST WHERE ID=1 AND ROWNUM = 1; "+
+		":1 := DBMS_LOB.SUBSTR(l, :2, :3); END;", ls.substr())
+
+	appendCmd := "DECLARE l LOBTEST.DATA%TYPE; BEGIN FOR r IN (SELECT DATA FROM LOBTEST WHERE ID=1 FOR UPDATE) LOOP " +
+		"l := r.DATA; DBMS_LOB.WRITEAPPEND(l, :1, :2); END LOOP; END;"
+	cmd, args := ls.block(true, nil)
+	assert.Equal(t, "UPDATE LOBTEST SET DATA=EMPTY_BLOB() WHERE ID=1", cmd)
+	assert.Nil(t, args)
+	cmd, _ = ls.block(false, nil)
+	assert.Equal(t, "", cmd)
+	cmd, args = ls.block(true, []byte("abc"))
+	assert.Equal(t, "BEGIN UPDATE LOBTEST SET DATA=EMPTY_BLOB() WHERE ID=1; "+appendCmd+" END;", cmd)
+	assert.Equal(t, []any{3, []byte("abc")}, args)
+	cmd, args = ls.block(false, []byte("def"))
+	assert.Equal(t, appendCmd, cmd)
+	assert.Equal(t, []any{3, []byte("def")}, args)
+
+	ls = newLobStatements(search, true)
+	assert.Equal(t, int32(clobBlocksize), ls.blocksize(0))
+	cmd, args = ls.block(true, []byte("äöü"))
+	assert.Equal(t, "BEGIN UPDATE LOBTEST SET DATA=EMPTY_CLOB() WHERE ID=1; "+appendCmd+" END;", cmd)
+	assert.Equal(t, []any{3, "äöü"}, args)
+}
+
+func TestLobColumnTypeQuery(t *testing.T) {
+	InitLog(t)
+
+	query, args := columnTypeQuery("lobtest", "data")
+	assert.Equal(t, "SELECT data_type FROM user_tab_columns WHERE table_name = :1 AND column_name = :2", query)
+	assert.Equal(t, []any{"LOBTEST", "DATA"}, args)
+	query, args = columnTypeQuery("admin.lobtest", "data")
+	assert.Equal(t, "SELECT data_type FROM all_tab_columns WHERE owner = :1 AND table_name = :2 AND column_name = :3", query)
+	assert.Equal(t, []any{"ADMIN", "LOBTEST", "DATA"}, args)
+}
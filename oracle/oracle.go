@@ -19,12 +19,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"math"
 	"strings"
 	"text/template"
 
 	_ "github.com/godror/godror"
-	"github.com/tknie/errorrepo"
 	"github.com/tknie/flynn/common"
 	"github.com/tknie/flynn/dbsql"
 	"github.com/tknie/log"
@@ -223,9 +221,14 @@ func (oracle *Oracle) ByteArrayAvailable() bool {
 	return false
 }
 
+// DriverType database driver type used for dialect specific SQL
+func (oracle *Oracle) DriverType() common.ReferenceType {
+	return common.OracleType
+}
+
 // Reference reference to oracle URL
 func (oracle *Oracle) Reference() (string, string) {
-	return "oracle", oracle.generateURL()
+	return layer, oracle.generateURL()
 }
 
 // ID current id used
@@ -284,9 +287,38 @@ func (oracle *Oracle) Delete(name string, remove *common.Entries) (int64, error)
 	return dbsql.Delete(oracle, name, remove)
 }
 
+// TableColumns get table column definitions
+func (oracle *Oracle) TableColumns(tableName string) ([]*common.Column, error) {
+	return dbsql.TableColumns(oracle, tableName)
+}
+
 // GetTableColumn get table columne names
 func (oracle *Oracle) GetTableColumn(tableName string) ([]string, error) {
-	return nil, errorrepo.NewError("DB065535")
+	log.Log.Debugf("Get table column ...")
+	dbOpen, err := oracle.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer oracle.Close()
+
+	db := dbOpen.(*sql.DB)
+	rows, err := db.Query(`SELECT column_name FROM user_tab_columns WHERE table_name = :1 ORDER BY column_id`,
+		strings.ToUpper(tableName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tableRows := make([]string, 0)
+	tableRow := ""
+	for rows.Next() {
+		err = rows.Scan(&tableRow)
+		if err != nil {
+			return nil, err
+		}
+		tableRows = append(tableRows, strings.ToLower(tableRow))
+	}
+
+	return tableRows, nil
 }
 
 // Query query database records with search or SELECT
@@ -319,9 +351,19 @@ func (oracle *Oracle) CreateTable(name string, columns any) error {
 	return dbsql.CreateTable(oracle, name, columns)
 }
 
-// AdaptTable adapt a new table
-func (oracle *Oracle) AdaptTable(string, any) error {
-	return errorrepo.NewError("DB065535")
+// AdaptTable adapt table to new structure
+func (oracle *Oracle) AdaptTable(name string, newStruct any) error {
+	return dbsql.AdaptTable(oracle, name, newStruct)
+}
+
+// PlanAdaptTable generate plan to adapt table to new structure
+func (oracle *Oracle) PlanAdaptTable(name string, newStruct any) (*common.AdaptPlan, error) {
+	return dbsql.PlanAdaptTable(oracle, name, newStruct)
+}
+
+// ApplyAdaptPlan apply adapt plan to table
+func (oracle *Oracle) ApplyAdaptPlan(plan *common.AdaptPlan, destructive bool) error {
+	return dbsql.ApplyAdaptPlan(oracle, plan, destructive)
 }
 
 // DeleteTable delete a table
@@ -344,6 +386,11 @@ func (oracle *Oracle) Batch(batch string) error {
 	return dbsql.Batch(oracle, batch)
 }
 
+// Execute execute SQL statement with parameters
+func (oracle *Oracle) Execute(statement string, args ...any) (int64, error) {
+	return dbsql.Execute(oracle, statement, args...)
+}
+
 // BatchSelect batch SQL query in table with values returned
 func (oracle *Oracle) BatchSelect(batch string) ([][]interface{}, error) {
 	return dbsql.BatchSelect(oracle, batch)
@@ -367,6 +414,8 @@ func (oracle *Oracle) BatchSelectFct(search *common.Query, fct common.ResultFunc
 	if search.DataStruct == nil {
 		_, err = search.ParseRows(rows, fct)
 	} else {
+		ti := common.CreateInterfaceNaming(search.DataStruct, search.Fields, search.Naming)
+		search.TypeInfo = ti
 		_, err = search.ParseStruct(rows, fct)
 	}
 	return err
@@ -379,6 +428,9 @@ func (oracle *Oracle) StartTransaction() (*sql.Tx, context.Context, error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	if oracle.tx != nil && oracle.IsTransaction() {
+		return oracle.tx, oracle.ctx, nil
+	}
 	oracle.ctx = context.Background()
 	oracle.tx, err = oracle.openDB.(*sql.DB).BeginTx(oracle.ctx, nil)
 	if err != nil {
@@ -400,59 +452,3 @@ func (oracle *Oracle) Rollback() error {
 	oracle.Transaction = false
 	return oracle.EndTransaction(false)
 }
-
-func (oracle *Oracle) Stream(search *common.Query, sf common.StreamFunction) error {
-	dbOpen, err := oracle.Open()
-	if err != nil {
-		return err
-	}
-	defer oracle.Close()
-
-	db := dbOpen.(*sql.DB)
-	offset := int32(1)
-	blocksize := search.Blocksize
-	dataMaxLen := int32(math.MaxInt32)
-
-	log.Log.Debugf("Start stream for %s for %s", search.Fields[0], search.TableName)
-	selectCmd := fmt.Sprintf("SELECT SUBSTRING(%s FROM %d FOR %d),LENGTH(%s) FROM %s WHERE %s",
-		search.Fields[0], offset, blocksize, search.Fields[0], search.TableName, search.Search)
-	for offset < dataMaxLen {
-		log.Log.Debugf("Query: %s", selectCmd)
-		rows, err := db.Query(selectCmd)
-		if err != nil {
-			log.Log.Errorf("Stream query error: %v", err)
-			return err
-		}
-		stream := &common.Stream{}
-		stream.Data = make([]byte, 0)
-		if !rows.Next() {
-			log.Log.Errorf("rows missing")
-			return errorrepo.NewError("DB000022")
-		}
-		if dataMaxLen == int32(math.MaxInt32) {
-			err = rows.Scan(&stream.Data, &dataMaxLen)
-		} else {
-			err = rows.Scan(&stream.Data)
-		}
-		if err != nil {
-			log.Log.Errorf("rows scan error: %s", err)
-			return err
-		}
-		err = sf(search, stream)
-		if err != nil {
-			log.Log.Errorf("stream function error: %s", err)
-			return err
-		}
-		offset += blocksize
-		if offset >= dataMaxLen {
-			break
-		}
-		if offset+blocksize > dataMaxLen {
-			blocksize = dataMaxLen - offset + 1
-		}
-
-		selectCmd = fmt.Sprintf("SELECT SUBSTRING(%s FROM %d FOR %d) FROM %s WHERE %s",
-			search.Fields[0], offset, blocksize, search.TableName, search.Search)
-	}
-	return nil
-}
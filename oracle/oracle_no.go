@@ -15,6 +15,7 @@
 package oracle
 
 import (
+	"io"
 	"math"
 
 	"github.com/tknie/errorrepo"
@@ -60,6 +61,11 @@ func (ada *oracle) URL() string {
 	return ""
 }
 
+// DriverType database driver type used for dialect specific SQL
+func (ada *oracle) DriverType() common.ReferenceType {
+	return common.OracleType
+}
+
 // Maps database maps, tables or views
 func (ada *oracle) Maps() ([]string, error) {
 	return nil, errorrepo.NewError("DB065535")
@@ -94,6 +100,11 @@ func (ada *oracle) Delete(name string, remove *common.Entries) (int64, error) {
 	return 0, errorrepo.NewError("DB065535")
 }
 
+// TableColumns get table column definitions
+func (ada *oracle) TableColumns(tableName string) ([]*common.Column, error) {
+	return nil, errorrepo.NewError("DB065535")
+}
+
 // GetTableColumn get table columne names
 func (ada *oracle) GetTableColumn(tableName string) ([]string, error) {
 	return nil, errorrepo.NewError("DB065535")
@@ -115,6 +126,16 @@ func (ada *oracle) AdaptTable(string, any) error {
 	return errorrepo.NewError("DB065535")
 }
 
+// PlanAdaptTable generate plan to adapt table to new structure
+func (ada *oracle) PlanAdaptTable(string, any) (*common.AdaptPlan, error) {
+	return nil, errorrepo.NewError("DB065535")
+}
+
+// ApplyAdaptPlan apply adapt plan to table
+func (ada *oracle) ApplyAdaptPlan(*common.AdaptPlan, bool) error {
+	return errorrepo.NewError("DB065535")
+}
+
 // DeleteTable delete a table
 func (ada *oracle) DeleteTable(string) error {
 	return errorrepo.NewError("DB065535")
@@ -125,6 +146,11 @@ func (ada *oracle) Batch(batch string) error {
 	return errorrepo.NewError("DB065535")
 }
 
+// Execute execute SQL statement with parameters
+func (ada *oracle) Execute(statement string, args ...any) (int64, error) {
+	return -1, errorrepo.NewError("DB065535")
+}
+
 // BatchSelect batch SQL query in table with values returned
 func (ada *oracle) BatchSelect(batch string) ([][]interface{}, error) {
 	return nil, errorrepo.NewError("DB065535")
@@ -150,3 +176,11 @@ func (ada *oracle) Rollback() error {
 func (ada *oracle) Stream(search *common.Query, sf common.StreamFunction) error {
 	return errorrepo.NewError("DB065535")
 }
+
+func (ada *oracle) StreamWrite(search *common.Query, reader io.Reader) (int64, error) {
+	return 0, errorrepo.NewError("DB065535")
+}
+
+func (ada *oracle) OpenLOB(search *common.Query) (common.LOBReader, error) {
+	return nil, errorrepo.NewError("DB065535")
+}
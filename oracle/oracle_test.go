@@ -7,10 +7,12 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/tknie/flynn/common"
+	"github.com/tknie/flynn/dbsql"
 	"github.com/tknie/log"
 )
 
@@ -166,3 +168,24 @@ func TestOracleRead(t *testing.T) {
 	})
 	assert.NoError(t, err)
 }
+
+func TestOracleTypeMapper(t *testing.T) {
+	InitLog(t)
+
+	columns, err := dbsql.CreateTableByStruct(typeMapper, &struct {
+		Name    string `flynn:"::40"`
+		Long    string `flynn:"::8000"`
+		Counter int
+		Big     uint64
+		Price   float64
+		Flag    bool
+		Data    []byte
+		Created time.Time
+	}{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Name VARCHAR2(40) NOT NULL, Long CLOB NOT NULL, Counter NUMBER(19) NOT NULL, "+
+		"Big NUMBER(20) NOT NULL, Price BINARY_DOUBLE NOT NULL, Flag NUMBER(1) NOT NULL, Data BLOB, "+
+		"Created TIMESTAMP WITH TIME ZONE NOT NULL", columns)
+	assert.Equal(t, "Title NVARCHAR2(100)", dbsql.CreateTableByColumns(typeMapper,
+		[]*common.Column{{Name: "Title", DataType: common.Unicode, Length: 100}}))
+}
@@ -0,0 +1,63 @@
+//go:build !flynn_nooracle
+// +build !flynn_nooracle
+
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package oracle
+
+import (
+	"reflect"
+
+	"github.com/tknie/flynn/common"
+)
+
+// typeMapper Oracle native types
+var typeMapper = &common.DialectTypes{
+	Types: map[common.DataType]string{
+		common.Alpha:            "VARCHAR2(%d)",
+		common.Text:             "CLOB",
+		common.Unicode:          "NVARCHAR2(%d)",
+		common.Integer:          "NUMBER(10)",
+		common.Decimal:          "NUMBER(%d,%d)",
+		common.Number:           "NUMBER(19)",
+		common.Bit:              "RAW(%d)",
+		common.Bytes:            "BLOB",
+		common.CurrentTimestamp: "TIMESTAMP WITH TIME ZONE",
+		common.Date:             "DATE",
+		common.BLOB:             "BLOB",
+		common.Character:        "CHAR(%d)",
+		common.Float:            "BINARY_DOUBLE",
+		common.Boolean:          "NUMBER(1)",
+	},
+	Kinds: map[reflect.Kind]string{
+		reflect.Int8:    "NUMBER(3)",
+		reflect.Int16:   "NUMBER(5)",
+		reflect.Int32:   "NUMBER(10)",
+		reflect.Int:     "NUMBER(19)",
+		reflect.Int64:   "NUMBER(19)",
+		reflect.Uint8:   "NUMBER(3)",
+		reflect.Uint16:  "NUMBER(5)",
+		reflect.Uint32:  "NUMBER(10)",
+		reflect.Uint:    "NUMBER(20)",
+		reflect.Uint64:  "NUMBER(20)",
+		reflect.Float32: "BINARY_FLOAT",
+		reflect.Float64: "BINARY_DOUBLE",
+		reflect.Bool:    "NUMBER(1)",
+	},
+	// VARCHAR2 limit without extended string size
+	MaxAlpha: 4000,
+}
+
+// TypeMapper dialect type mapper used to create tables
+func (oracle *Oracle) TypeMapper() common.TypeMapper {
+	return typeMapper
+}
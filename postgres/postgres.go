@@ -17,7 +17,9 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"runtime/debug"
 	"strconv"
@@ -146,6 +148,11 @@ func (pg *PostGres) ByteArrayAvailable() bool {
 	return true
 }
 
+// DriverType database driver type used for dialect specific SQL
+func (pg *PostGres) DriverType() common.ReferenceType {
+	return common.PostgresType
+}
+
 // ID current id used
 func (pg *PostGres) ID() common.RegDbID {
 	return pg.RegDbID
@@ -457,10 +464,15 @@ func (pg *PostGres) Delete(name string, remove *common.Entries) (rowsAffected in
 		ctx = pg.ctx
 	}
 
+	softDelete := remove.SoftDeleteColumn()
 	if remove.Criteria != "" {
 		deleteCmd := "DELETE FROM " + name + " WHERE " + remove.Criteria
+		var av []any
+		if softDelete != "" {
+			deleteCmd, av = dbsql.GenerateSoftDelete(pg.IndexNeeded(), name, 0, remove, softDelete)
+		}
 		log.Log.Debugf("Delete cmd: %s", deleteCmd)
-		res, err := tx.Exec(ctx, deleteCmd)
+		res, err := tx.Exec(ctx, deleteCmd, av...)
 		if err != nil {
 			log.Log.Debugf("Delete error: %v", err)
 			pg.EndTransaction(false)
@@ -469,7 +481,10 @@ func (pg *PostGres) Delete(name string, remove *common.Entries) (rowsAffected in
 		rowsAffected += res.RowsAffected()
 	} else {
 		for i := 0; i < len(remove.Values); i++ {
-			deleteCmd, av := dbsql.GenerateDelete(pg.IndexNeeded(), name, 0, remove)
+			deleteCmd, av := dbsql.GenerateDelete(pg.IndexNeeded(), name, i, remove)
+			if softDelete != "" {
+				deleteCmd, av = dbsql.GenerateSoftDelete(pg.IndexNeeded(), name, i, remove, softDelete)
+			}
 			log.Log.Debugf("Delete cmd: %s -> %#v", deleteCmd, av)
 			res, err := tx.Exec(ctx, deleteCmd, av...)
 			// tx.ExecContext(ctx, deleteCmd, av...)
@@ -494,6 +509,11 @@ func (pg *PostGres) Delete(name string, remove *common.Entries) (rowsAffected in
 	return
 }
 
+// TableColumns get table column definitions
+func (pg *PostGres) TableColumns(tableName string) ([]*common.Column, error) {
+	return dbsql.TableColumns(pg, tableName)
+}
+
 // GetTableColumn get table columne names
 func (pg *PostGres) GetTableColumn(tableName string) ([]string, error) {
 	log.Log.Debugf("Get table column ...")
@@ -649,9 +669,9 @@ func (pg *PostGres) CreateTable(name string, col any) error {
 	createCmd := `CREATE TABLE ` + name + ` (`
 	switch columns := col.(type) {
 	case []*common.Column:
-		createCmd += dbsql.CreateTableByColumns(pg.ByteArrayAvailable(), columns)
+		createCmd += dbsql.CreateTableByColumns(pg.TypeMapper(), columns)
 	default:
-		c, err := dbsql.CreateTableByStruct(pg.ByteArrayAvailable(), col)
+		c, err := dbsql.CreateTableByStructNaming(pg.TypeMapper(), pg.ID().NamingStrategy(), col)
 		if err != nil {
 			log.Log.Errorf("Error parsing structure: %v", err)
 			return err
@@ -676,39 +696,19 @@ func (pg *PostGres) CreateTable(name string, col any) error {
 	return nil
 }
 
-// AdaptTable adapt a new table
-func (pg *PostGres) AdaptTable(name string, col any) error {
-	log.Log.Debugf("%s: Adapt SQL table", pg.ID())
-	layer, url := pg.Reference()
-	db, err := sql.Open(layer, url)
-	if err != nil {
-		return err
-	}
-	defer db.Close()
+// AdaptTable adapt table to new structure
+func (pg *PostGres) AdaptTable(name string, newStruct any) error {
+	return dbsql.AdaptTable(pg, name, newStruct)
+}
 
-	columnCurrent, err := pg.ID().GetTableColumn(name)
-	if err != nil {
-		return err
-	}
-	fmt.Println(columnCurrent)
-	log.Log.Debugf("Got columns: %v", columnCurrent)
-	columStruct, err := dbsql.SqlDataType(false, col, columnCurrent)
-	if err != nil {
-		return err
-	}
-	fmt.Println(columStruct)
-	for _, f := range strings.Split(columStruct, ",") {
+// PlanAdaptTable generate plan to adapt table to new structure
+func (pg *PostGres) PlanAdaptTable(name string, newStruct any) (*common.AdaptPlan, error) {
+	return dbsql.PlanAdaptTable(pg, name, newStruct)
+}
 
-		adaptCmd := `ALTER TABLE ` + name + ` ADD ` + f
-		log.Log.Debugf("Create cmd %s", adaptCmd)
-		_, err = db.Query(adaptCmd)
-		if err != nil {
-			log.Log.Errorf("Error returned by SQL: %v", err)
-			return err
-		}
-	}
-	log.Log.Debugf("Table adapted")
-	return nil
+// ApplyAdaptPlan apply adapt plan to table
+func (pg *PostGres) ApplyAdaptPlan(plan *common.AdaptPlan, destructive bool) error {
+	return dbsql.ApplyAdaptPlan(pg, plan, destructive)
 }
 
 // DeleteTable delete a table
@@ -769,10 +769,15 @@ func (pg *PostGres) Insert(name string, insert *common.Entries) (returning [][]a
 	var insertFields []string
 	if insert.DataStruct != nil {
 		insertValues = make([][]any, 0)
-		dynamic := common.CreateInterface(insert.DataStruct, insert.Fields)
+		dynamic := common.CreateInterfaceNaming(insert.DataStruct, insert.Fields, insert.Naming)
+		dynamic.NativeArrays = true
 		insertFields = dynamic.RowFields
 		for _, vi := range insert.Values {
-			v, err := dynamic.CreateValues(vi[0])
+			value, err := dynamic.PrepareInsert(vi[0])
+			if err != nil {
+				return nil, err
+			}
+			v, err := dynamic.CreateValues(value)
 			if err != nil {
 				return nil, err
 			}
@@ -781,7 +786,7 @@ func (pg *PostGres) Insert(name string, insert *common.Entries) (returning [][]a
 			insertValues = append(insertValues, v)
 		}
 	} else {
-		insertFields = insert.Fields
+		insertFields = insert.Naming.ColumnNames(insert.Fields)
 		insertValues = insert.Values
 	}
 	log.Log.Debugf("Final values: %#v", insertValues)
@@ -807,7 +812,7 @@ func (pg *PostGres) Insert(name string, insert *common.Entries) (returning [][]a
 			if i > 0 {
 				insertCmd += ","
 			}
-			insertCmd += r
+			insertCmd += insert.Naming.ColumnName(r)
 		}
 	}
 	log.Log.Debugf("%s Insert pre-CMD: %s", pg.ID().String(), insertCmd)
@@ -882,7 +887,8 @@ func scanRow(row pgx.Row, cols int) ([]any, error) {
 }
 
 func scanStruct(row pgx.Row, insert *common.Entries) ([]any, error) {
-	typeInfo := common.CreateInterface(insert.DataStruct, insert.Returning)
+	typeInfo := common.CreateInterfaceNaming(insert.DataStruct, insert.Returning, insert.Naming)
+	typeInfo.NativeArrays = true
 	// copy, values, scanValues := typeInfo.CreateQueryValues()
 	vd, err := typeInfo.CreateQueryValues()
 	if err != nil {
@@ -928,16 +934,23 @@ func (pg *PostGres) Update(name string, updateInfo *common.Entries) (returning [
 	}
 	var insertFields []string
 	var updateValues [][]any
+	var versions []*common.UpdateVersion
 	if updateInfo.DataStruct != nil {
 		updateValues = make([][]any, 0)
-		dynamic := common.CreateInterface(updateInfo.DataStruct, updateInfo.Fields)
+		dynamic := common.CreateUpdateInterface(updateInfo)
+		dynamic.NativeArrays = true
 		insertFields = dynamic.RowFields
 		for _, vi := range updateInfo.Values {
-			v, err := dynamic.CreateValues(vi[0])
+			value, version, err := dynamic.PrepareUpdate(vi[0])
+			if err != nil {
+				return nil, -1, err
+			}
+			v, err := dynamic.CreateValues(value)
 			if err != nil {
 				return nil, -1, err
 			}
 			updateValues = append(updateValues, v)
+			versions = append(versions, version)
 			log.Log.Debugf("Row   fields: %#v", insertFields)
 			log.Log.Debugf("Value fields: %#v", updateValues)
 		}
@@ -945,29 +958,48 @@ func (pg *PostGres) Update(name string, updateInfo *common.Entries) (returning [
 		updateValues = updateInfo.Values
 	}
 	updateCmd, whereFields := dbsql.GenerateUpdate(pg.IndexNeeded(), name, updateInfo)
+	returningCmd := ""
 	if len(updateInfo.Returning) > 0 {
-		updateCmd += " RETURNING "
+		returningCmd += " RETURNING "
 		for i, r := range updateInfo.Returning {
 			if i > 0 {
-				updateCmd += ","
+				returningCmd += ","
 			}
-			updateCmd += r
+			returningCmd += updateInfo.Naming.ColumnName(r)
 		}
 	}
 
 	returning = make([][]any, 0)
 	for i, v := range updateValues {
+		var whereClause string
+		if updateInfo.DataStruct != nil {
+			whereClause = dbsql.CreateWhereValues(updateInfo, insertFields, v, whereFields)
+		} else {
+			whereClause = dbsql.CreateWhere(i, updateInfo, whereFields)
+		}
+		var version *common.UpdateVersion
+		if i < len(versions) {
+			version = versions[i]
+		}
+		if version != nil {
+			whereClause = dbsql.VersionWhere(true, whereClause, version, len(v)+1)
+			v = append(v, version.Old)
+		}
 		av := v
-		whereClause := dbsql.CreateWhere(i, updateInfo, whereFields)
-		ic := updateCmd + whereClause
+		ic := updateCmd + whereClause + returningCmd
 		log.Log.Debugf("Update call: %s", ic)
 		log.Log.Debugf("Update values: %d -> %#v tx=%v %v", len(v), v, tx, ctx)
 		if len(updateInfo.Returning) > 0 {
-			row := tx.QueryRow(ctx, updateCmd, av...)
+			row := tx.QueryRow(ctx, ic, av...)
 			if updateInfo.DataStruct != nil {
 				log.Log.Debugf("Use data struct for returning")
 				rv, err := scanStruct(row, updateInfo)
 				if err != nil {
+					common.ResetVersions(versions)
+					if version != nil && errors.Is(err, pgx.ErrNoRows) {
+						pg.EndTransaction(false)
+						return nil, 0, common.VersionConflict(name, version.Old)
+					}
 					trErr := pg.EndTransaction(false)
 					log.Log.Debugf("Error insert CMD: %v of %s and cmd %s trErr=%v",
 						err, name, updateCmd, trErr)
@@ -988,9 +1020,16 @@ func (pg *PostGres) Update(name string, updateInfo *common.Entries) (returning [
 			res, err := tx.Exec(ctx, ic, v...)
 			if err != nil {
 				log.Log.Debugf("Update error: %s -> %v", ic, err)
+				common.ResetVersions(versions)
 				pg.EndTransaction(false)
 				return nil, 0, err
 			}
+			if version != nil && res.RowsAffected() == 0 {
+				log.Log.Debugf("Update version conflict: %s", ic)
+				common.ResetVersions(versions)
+				pg.EndTransaction(false)
+				return nil, 0, common.VersionConflict(name, version.Old)
+			}
 			rowsAffected += res.RowsAffected()
 		}
 		log.Log.Debugf("Rows affected %d", rowsAffected)
@@ -1024,14 +1063,52 @@ func (pg *PostGres) Batch(batch string) error {
 	}
 	defer rows.Close()
 	for rows.Next() {
-		if rows.Err() != nil {
-			log.Log.Debugf("Batch SQL error: %v", rows.Err())
-			return rows.Err()
-		}
+		// result rows of the batch are not needed
+	}
+	if rows.Err() != nil {
+		log.Log.Debugf("Batch SQL error: %v", rows.Err())
+		return rows.Err()
 	}
 	return nil
 }
 
+// Execute execute SQL statement with parameters. If a transaction is
+// started the statement is part of the transaction.
+func (pg *PostGres) Execute(statement string, args ...any) (rowsAffected int64, err error) {
+	transaction := pg.IsTransaction()
+	var ctx context.Context
+	var tx pgx.Tx
+	if !transaction {
+		tx, ctx, err = pg.StartTransaction()
+		if err != nil {
+			return -1, err
+		}
+		defer pg.Close()
+	} else {
+		log.Log.Debugf("Tx used pg=%p/tx=%p", pg, pg.tx)
+		tx = pg.tx
+		ctx = pg.ctx
+	}
+	log.Log.Debugf("Execute cmd: %s -> %#v", statement, args)
+	res, err := tx.Exec(ctx, statement, args...)
+	if err != nil {
+		log.Log.Debugf("Execute error: %v", err)
+		// transactions of the caller are ended by the caller
+		if !transaction {
+			pg.EndTransaction(false)
+		}
+		return -1, err
+	}
+	rowsAffected = res.RowsAffected()
+	if !transaction {
+		err = pg.EndTransaction(true)
+		if err != nil {
+			return -1, err
+		}
+	}
+	return
+}
+
 // BatchSelect batch SQL query in table with values returned
 func (pg *PostGres) BatchSelect(batch string) ([][]interface{}, error) {
 	layer, url := pg.Reference()
@@ -1061,7 +1138,10 @@ func (pg *PostGres) BatchSelect(batch string) ([][]interface{}, error) {
 		if err != nil {
 			return nil, err
 		}
-		data = common.Unpointer(data)
+		data, err = common.ConvertTypeData(ct, data)
+		if err != nil {
+			return nil, err
+		}
 		result = append(result, data)
 	}
 	return result, nil
@@ -1092,7 +1172,9 @@ func (pg *PostGres) BatchSelectFct(search *common.Query, fct common.ResultFuncti
 	if search.DataStruct == nil {
 		_, err = pg.ParseRows(search, rows, fct)
 	} else {
-		search.TypeInfo = common.CreateInterface(search.DataStruct, search.Fields)
+		ti := common.CreateInterfaceNaming(search.DataStruct, search.Fields, search.Naming)
+		ti.NativeArrays = true
+		search.TypeInfo = ti
 		_, err = pg.ParseStruct(search, rows, fct)
 	}
 	return err
@@ -1207,3 +1289,66 @@ func (pg *PostGres) Stream(search *common.Query, sf common.StreamFunction) error
 	log.Log.Debugf("Stream finished")
 	return nil
 }
+
+// StreamWrite write the reader data in blocks into the field of the records
+// matching the search, following blocks are appended by concatenation
+func (pg *PostGres) StreamWrite(search *common.Query, reader io.Reader) (int64, error) {
+	transaction := pg.IsTransaction()
+	var ctx context.Context
+	var tx pgx.Tx
+	var err error
+	if !transaction {
+		tx, ctx, err = pg.StartTransaction()
+		if err != nil {
+			return 0, err
+		}
+		defer pg.Close()
+	} else {
+		tx = pg.tx
+		ctx = pg.ctx
+	}
+	if tx == nil {
+		return 0, errorrepo.NewError("DB000031")
+	}
+	field := search.Fields[0]
+	n, err := common.StreamBlocks(reader, search.Blocksize, func(offset int64, block []byte) error {
+		cmd := fmt.Sprintf("UPDATE %s SET %s=%s||$1 WHERE %s", search.TableName, field, field, search.Search)
+		if offset == 0 {
+			cmd = fmt.Sprintf("UPDATE %s SET %s=$1 WHERE %s", search.TableName, field, search.Search)
+		}
+		log.Log.Debugf("Stream write %d bytes at %d: %s", len(block), offset, cmd)
+		res, err := tx.Exec(ctx, cmd, block)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return errorrepo.NewError("DB000050", search.TableName, search.Search)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Log.Debugf("Stream write error: %v", err)
+		pg.EndTransaction(false)
+		return n, err
+	}
+	if !transaction {
+		err = pg.EndTransaction(true)
+		if err != nil {
+			return n, err
+		}
+	}
+	log.Log.Debugf("Stream write finished: %d bytes", n)
+	return n, nil
+}
+
+// OpenLOB open reader of the LOB field of the search, blocks are read
+// using substring
+func (pg *PostGres) OpenLOB(search *common.Query) (common.LOBReader, error) {
+	field := search.Fields[0]
+	return dbsql.OpenLOB(pg, search,
+		fmt.Sprintf("SELECT length(%s) FROM %s WHERE %s", field, search.TableName, search.Search),
+		func(offset int64, length int32) string {
+			return fmt.Sprintf("SELECT substring(%s,%d,%d) FROM %s WHERE %s",
+				field, offset+1, length, search.TableName, search.Search)
+		})
+}
@@ -15,6 +15,7 @@
 package postgres
 
 import (
+	"io"
 	"math"
 
 	"github.com/tknie/errorrepo"
@@ -49,6 +50,11 @@ func (ada *postgres) URL() string {
 	return ""
 }
 
+// DriverType database driver type used for dialect specific SQL
+func (ada *postgres) DriverType() common.ReferenceType {
+	return common.PostgresType
+}
+
 // Maps database maps, tables or views
 func (ada *postgres) Maps() ([]string, error) {
 	return nil, errorrepo.NewError("DB065535")
@@ -83,6 +89,11 @@ func (ada *postgres) Delete(name string, remove *common.Entries) (int64, error)
 	return 0, errorrepo.NewError("DB065535")
 }
 
+// TableColumns get table column definitions
+func (ada *postgres) TableColumns(tableName string) ([]*common.Column, error) {
+	return nil, errorrepo.NewError("DB065535")
+}
+
 // GetTableColumn get table columne names
 func (ada *postgres) GetTableColumn(tableName string) ([]string, error) {
 	return nil, errorrepo.NewError("DB065535")
@@ -104,6 +115,16 @@ func (ada *postgres) AdaptTable(string, any) error {
 	return errorrepo.NewError("DB065535")
 }
 
+// PlanAdaptTable generate plan to adapt table to new structure
+func (ada *postgres) PlanAdaptTable(string, any) (*common.AdaptPlan, error) {
+	return nil, errorrepo.NewError("DB065535")
+}
+
+// ApplyAdaptPlan apply adapt plan to table
+func (ada *postgres) ApplyAdaptPlan(*common.AdaptPlan, bool) error {
+	return errorrepo.NewError("DB065535")
+}
+
 // DeleteTable delete a table
 func (ada *postgres) DeleteTable(string) error {
 	return errorrepo.NewError("DB065535")
@@ -114,6 +135,11 @@ func (ada *postgres) Batch(batch string) error {
 	return errorrepo.NewError("DB065535")
 }
 
+// Execute execute SQL statement with parameters
+func (ada *postgres) Execute(statement string, args ...any) (int64, error) {
+	return -1, errorrepo.NewError("DB065535")
+}
+
 // BatchSelect batch SQL query in table with values returned
 func (ada *postgres) BatchSelect(batch string) ([][]interface{}, error) {
 	return nil, errorrepo.NewError("DB065535")
@@ -139,3 +165,11 @@ func (ada *postgres) Rollback() error {
 func (ada *postgres) Stream(search *common.Query, sf common.StreamFunction) error {
 	return errorrepo.NewError("DB065535")
 }
+
+func (ada *postgres) StreamWrite(search *common.Query, reader io.Reader) (int64, error) {
+	return 0, errorrepo.NewError("DB065535")
+}
+
+func (ada *postgres) OpenLOB(search *common.Query) (common.LOBReader, error) {
+	return nil, errorrepo.NewError("DB065535")
+}
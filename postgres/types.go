@@ -0,0 +1,62 @@
+//go:build !flynn_nopostgres
+// +build !flynn_nopostgres
+
+/*
+* Copyright 2022-2024 Thorsten A. Knieling
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*    http://www.apache.org/licenses/LICENSE-2.0
+*
+ */
+
+package postgres
+
+import (
+	"reflect"
+
+	"github.com/tknie/flynn/common"
+)
+
+// typeMapper PostgreSQL native types
+var typeMapper = &common.DialectTypes{
+	Types: map[common.DataType]string{
+		common.Alpha:            "VARCHAR(%d)",
+		common.Text:             "TEXT",
+		common.Unicode:          "VARCHAR(%d)",
+		common.Integer:          "INTEGER",
+		common.Decimal:          "NUMERIC(%d,%d)",
+		common.Number:           "BIGINT",
+		common.Bit:              "BIT(%d)",
+		common.Bytes:            "BYTEA",
+		common.CurrentTimestamp: "TIMESTAMPTZ",
+		common.Date:             "DATE",
+		common.BLOB:             "BYTEA",
+		common.Character:        "CHAR(%d)",
+		common.Float:            "DOUBLE PRECISION",
+		common.Boolean:          "BOOLEAN",
+	},
+	Kinds: map[reflect.Kind]string{
+		reflect.Int8:    "SMALLINT",
+		reflect.Int16:   "SMALLINT",
+		reflect.Int32:   "INTEGER",
+		reflect.Int:     "BIGINT",
+		reflect.Int64:   "BIGINT",
+		reflect.Uint8:   "SMALLINT",
+		reflect.Uint16:  "INTEGER",
+		reflect.Uint32:  "BIGINT",
+		reflect.Uint:    "NUMERIC(20)",
+		reflect.Uint64:  "NUMERIC(20)",
+		reflect.Float32: "REAL",
+		reflect.Float64: "DOUBLE PRECISION",
+		reflect.Bool:    "BOOLEAN",
+	},
+	Arrays: true,
+}
+
+// TypeMapper dialect type mapper used to create tables
+func (pg *PostGres) TypeMapper() common.TypeMapper {
+	return typeMapper
+}